@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// ClientIdClaim is "client_id" claim of the client the token was issued to (RFC 8693, RFC 9068)
+	ClientIdClaim = "client_id"
+)
+
+// NewAccessTokenTemplate returns Template of RFC 9068 access tokens issued by issuer and valid for ttl.
+// Tokens have "typ" at+jwt, "iss", "iat", "exp" and random "jti". Building a token fails unless
+// "sub", "aud" and "client_id" are set as well
+func NewAccessTokenTemplate(a alg.Algorithm, issuer string, ttl time.Duration, options ...TemplateOption) Template {
+	options = append([]TemplateOption{
+		IssuedBy(issuer),
+		ValidFor(ttl),
+		WithTemplateType(AccessTokenType),
+	}, options...)
+
+	t := NewTemplate(a, options...)
+	t.check = checkAccessTokenClaims
+	return t
+}
+
+// ValidateAccessToken configures Validator to enforce RFC 9068 access token profile for resource server:
+// "typ" must be at+jwt, the token must be signed, "iss" must be issuer, "aud" must contain resource,
+// "exp", "sub", "client_id", "iat" and "jti" are required, Security Event Tokens are rejected.
+// Parser must accept AccessTokenType as well, e.g. NewParser(RequireType(AccessTokenType))
+func ValidateAccessToken(issuer, resource string) ValidatorOption {
+	return func(v *Validator) {
+		WithIssuer(issuer)(v)
+		WithAudience(resource)(v)
+		RequireExpiration()(v)
+		RejectSecurityEvents()(v)
+		WithCheck(checkAccessToken)(v)
+	}
+}
+
+func checkAccessToken(t Token) error {
+	if !t.Header.Type.Is(AccessTokenType) {
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedType, t.Header.Type)
+	}
+	if t.Header.Algorithm == alg.None {
+		return fmt.Errorf("%w: access token must be signed", ErrIncorrectSignature)
+	}
+
+	return checkAccessTokenClaims(t)
+}
+
+func checkAccessTokenClaims(t Token) error {
+	c := t.Claims
+	required := []struct {
+		name    string
+		present bool
+	}{
+		{"iss", len(c.Issuer) > 0},
+		{"exp", c.ExpiresAt != nil},
+		{"aud", len(c.Audience) > 0},
+		{"sub", len(c.Subject) > 0},
+		{"client_id", c.Has(ClientIdClaim)},
+		{"iat", c.IssuedAt != nil},
+		{"jti", len(c.Id) > 0},
+	}
+	for _, claim := range required {
+		if !claim.present {
+			return fmt.Errorf("%w: %s", ErrMissingClaim, claim.name)
+		}
+	}
+
+	if _, err := c.GetString(ClientIdClaim); err != nil {
+		return err
+	}
+	if _, err := c.Scopes(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenTemplate(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	template := NewAccessTokenTemplate(alg.HS256, "https://auth.example.com", 5*time.Minute,
+		WithTemplateClock(func() time.Time { return now }))
+
+	t.Run("issue", func(t *testing.T) {
+		s, err := template.Build().
+			Subject("user").
+			Audience("https://api.example.com").
+			ClientId("client").
+			Scope("read", "write").
+			AuthTime(now.Add(-time.Minute)).
+			Acr("phr").
+			Amr("pwd", "otp").
+			WriteString()
+		require.NoError(t, err)
+
+		token, err := Parse([]byte(s), RequireType(AccessTokenType))
+		require.NoError(t, err)
+		assert.Equal(t, AccessTokenType, token.Header.Type)
+		assert.Equal(t, "https://auth.example.com", token.Claims.Issuer)
+		assert.Equal(t, now.Add(5*time.Minute).Unix(), token.Claims.ExpiresAt.Unix())
+		assert.NotEmpty(t, token.Claims.Id)
+
+		clientId, err := token.Claims.GetString(ClientIdClaim)
+		require.NoError(t, err)
+		assert.Equal(t, "client", clientId)
+
+		scopes, err := token.Claims.Scopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read", "write"}, scopes)
+
+		authTime, err := token.Claims.GetTime(AuthTimeClaim)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-time.Minute).Unix(), authTime.Unix())
+
+		acr, err := token.Claims.GetString(AcrClaim)
+		require.NoError(t, err)
+		assert.Equal(t, "phr", acr)
+
+		amr, err := token.Claims.GetStrings(AmrClaim)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pwd", "otp"}, amr)
+	})
+	t.Run("missing claims", func(t *testing.T) {
+		_, err := template.Build().Subject("user").Audience("api").Token()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+
+		_, err = template.Build().Subject("user").ClientId("client").Token()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+
+		_, err = template.Build().Audience("api").ClientId("client").WriteString()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+
+		_, err = template.Build().Subject("user").Audience("api").Claim(ClientIdClaim, 1).Token()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	template := NewAccessTokenTemplate(alg.HS256, "https://auth.example.com", 5*time.Minute, WithTemplateClock(clock))
+	validator := NewValidator(WithClock(clock), ValidateAccessToken("https://auth.example.com", "https://api.example.com"))
+
+	build := func(t *testing.T) Token {
+		token, err := template.Build().Subject("user").Audience("https://api.example.com").ClientId("client").Token()
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		token := build(t)
+		assert.NoError(t, validator.Validate(token))
+
+		token.Header.Type = "application/AT+JWT"
+		assert.NoError(t, validator.Validate(token))
+	})
+	t.Run("type", func(t *testing.T) {
+		token := build(t)
+		token.Header.Type = JsonWebTokenType
+		assert.True(t, errors.Is(validator.Validate(token), ErrUnsupportedType))
+	})
+	t.Run("unsigned", func(t *testing.T) {
+		token := build(t)
+		token.Header.Algorithm = alg.None
+		assert.True(t, errors.Is(validator.Validate(token), ErrIncorrectSignature))
+	})
+	t.Run("resource", func(t *testing.T) {
+		token := build(t)
+		token.Claims.Audience = Audience{"https://other.example.com"}
+		assert.True(t, errors.Is(validator.Validate(token), ErrAudienceMismatch))
+	})
+	t.Run("required claims", func(t *testing.T) {
+		for _, claim := range []string{"sub", "client_id", "iat", "jti", "exp"} {
+			token := build(t)
+			require.NoError(t, token.Claims.Delete(claim))
+			assert.True(t, errors.Is(validator.Validate(token), ErrMissingClaim), claim)
+		}
+	})
+	t.Run("malformed scope", func(t *testing.T) {
+		token := build(t)
+		require.NoError(t, token.Claims.Put(ScopeClaim, []string{"read"}))
+		assert.True(t, errors.Is(validator.Validate(token), ErrInvalidClaim))
+	})
+}
+
+func TestMiddleware_AccessToken(t *testing.T) {
+	registerTestHmac(t)
+	template := NewAccessTokenTemplate(alg.HS256, "https://auth.example.com", 5*time.Minute)
+	m := NewMiddleware(
+		NewParser(RequireType(AccessTokenType)),
+		NewValidator(ValidateAccessToken("https://auth.example.com", "https://api.example.com")),
+		WithAuthorizer(RequireAllScopes("read")),
+	)
+
+	s, err := template.Build().Subject("user").Audience("https://api.example.com").ClientId("client").Scope("read").WriteString()
+	require.NoError(t, err)
+	w, seen := serveTestRequest(m, "Bearer "+s)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	require.NotNil(t, seen)
+
+	s, err = Build(alg.HS256).Issuer("https://auth.example.com").Subject("user").Audience("https://api.example.com").
+		ExpiresIn(time.Minute).RandomId().Claim(ClientIdClaim, "client").Claim(ScopeClaim, "read").WriteString()
+	require.NoError(t, err)
+	w, _ = serveTestRequest(m, "Bearer "+s)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,5 +1,10 @@
 package alg
 
+import (
+	"crypto"
+	"fmt"
+)
+
 type Algorithm string
 
 const (
@@ -45,3 +50,18 @@ type Signer interface {
 type Verifier interface {
 	Verify(payload, signature []byte) (bool, error)
 }
+
+// Hash returns hash function of the algorithm signature, e.g. SHA-256 for HS256, RS256, ES256 and PS256.
+// EdDSA is mapped to SHA-512 used by Ed25519
+func (a Algorithm) Hash() (crypto.Hash, error) {
+	switch a {
+	case HS256, RS256, ES256, PS256:
+		return crypto.SHA256, nil
+	case HS384, RS384, ES384, PS384:
+		return crypto.SHA384, nil
+	case HS512, RS512, ES512, PS512, EdDSA:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("algorithm %s has no hash", a)
+	}
+}
@@ -0,0 +1,37 @@
+package alg
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithm_Hash(t *testing.T) {
+	tests := map[Algorithm]crypto.Hash{
+		HS256: crypto.SHA256,
+		RS256: crypto.SHA256,
+		ES256: crypto.SHA256,
+		PS256: crypto.SHA256,
+		HS384: crypto.SHA384,
+		RS384: crypto.SHA384,
+		ES384: crypto.SHA384,
+		PS384: crypto.SHA384,
+		HS512: crypto.SHA512,
+		RS512: crypto.SHA512,
+		ES512: crypto.SHA512,
+		PS512: crypto.SHA512,
+		EdDSA: crypto.SHA512,
+	}
+	for a, expected := range tests {
+		t.Run(string(a), func(t *testing.T) {
+			hash, err := a.Hash()
+			require.NoError(t, err)
+			assert.Equal(t, expected, hash)
+		})
+	}
+
+	_, err := None.Hash()
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package alg
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoCertificates = errors.New("certificate chain is empty")
+)
+
+// CertificateHolder is implemented by signers bound to X.509 certificate chain
+type CertificateHolder interface {
+	Certificates() []*x509.Certificate
+}
+
+// CertifiedSigner is Signer bound to certificate chain, tokens signed with it advertise the chain in "x5c"
+type CertifiedSigner struct {
+	Signer
+	chain []*x509.Certificate
+}
+
+// NewCertifiedSigner binds chain (leaf first) to signer of algorithm a.
+// It checks that the leaf certificate public key verifies signer signatures
+func NewCertifiedSigner(a Algorithm, signer Signer, chain ...*x509.Certificate) (CertifiedSigner, error) {
+	if signer == nil {
+		return CertifiedSigner{}, ErrNilKey
+	}
+	if len(chain) == 0 || chain[0] == nil {
+		return CertifiedSigner{}, ErrNoCertificates
+	}
+
+	// signer key strength was checked by its constructor, here only key match matters
+	verifier, err := NewVerifier(a, chain[0].PublicKey, AllowWeakKeys())
+	if err != nil {
+		return CertifiedSigner{}, err
+	}
+
+	probe := []byte(chain[0].Subject.String())
+	signature, err := signer.Sign(probe)
+	if err != nil {
+		return CertifiedSigner{}, err
+	}
+
+	ok, err := verifier.Verify(probe, signature)
+	if err != nil {
+		return CertifiedSigner{}, err
+	}
+	if !ok {
+		return CertifiedSigner{}, fmt.Errorf("certificate %s does not match signer key", chain[0].Subject)
+	}
+
+	return CertifiedSigner{Signer: signer, chain: chain}, nil
+}
+
+// Certificates returns bound certificate chain, leaf first
+func (c CertifiedSigner) Certificates() []*x509.Certificate {
+	return c.chain
+}
+
+// JWK returns JWK of the signer if it is JWKProvider, otherwise JWK of the leaf certificate public key
+func (c CertifiedSigner) JWK() (JWK, error) {
+	if provider, ok := c.Signer.(JWKProvider); ok {
+		return provider.JWK()
+	}
+
+	return NewJWK(c.chain[0].PublicKey)
+}
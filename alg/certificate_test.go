@@ -0,0 +1,66 @@
+package alg
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selfSignedCertificate(t *testing.T, key *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "signer"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestNewCertifiedSigner(t *testing.T) {
+	es256, err := NewECDSA(ES256, ecdsa256PrivateKey, ecdsa256PublicKey)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		cert := selfSignedCertificate(t, ecdsa256PrivateKey)
+
+		signer, err := NewCertifiedSigner(ES256, es256, cert)
+		require.NoError(t, err)
+		assert.Equal(t, []*x509.Certificate{cert}, signer.Certificates())
+		assert.Equal(t, es256.Size(), signer.Size())
+
+		var _ CertificateHolder = signer
+	})
+	t.Run("key mismatch", func(t *testing.T) {
+		cert := selfSignedCertificate(t, ecdsa256PrivateKeyAlternative)
+
+		_, err := NewCertifiedSigner(ES256, es256, cert)
+		require.Error(t, err)
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewCertifiedSigner(ES256, es256)
+		require.ErrorIs(t, err, ErrNoCertificates)
+
+		_, err = NewCertifiedSigner(ES256, nil, selfSignedCertificate(t, ecdsa256PrivateKey))
+		require.ErrorIs(t, err, ErrNilKey)
+	})
+	t.Run("algorithm mismatch", func(t *testing.T) {
+		_, err := NewCertifiedSigner(RS256, es256, selfSignedCertificate(t, ecdsa256PrivateKey))
+		require.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package alg
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"errors"
 	"fmt"
@@ -17,38 +18,64 @@ type ECDSA struct {
 }
 
 var (
-	ErrNilKey = errors.New("key is nil")
+	ErrNilKey       = errors.New("key is nil")
+	ErrNoPrivateKey = errors.New("private key is not set, algorithm can only verify")
 )
 
+// NewECDSA returns ECDSA algorithm a. Keys must be on the curve required by the algorithm and form a pair
 func NewECDSA(a Algorithm, privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) (ECDSA, error) {
-	if privateKey == nil || publicKey == nil {
+	if privateKey == nil {
+		return ECDSA{}, ErrNilKey
+	}
+
+	e, err := NewECDSAVerifier(a, publicKey)
+	if err != nil {
+		return ECDSA{}, err
+	}
+	if err = checkECDSAKeyPair(privateKey, publicKey); err != nil {
+		return ECDSA{}, err
+	}
+
+	e.privateKey = privateKey
+	return e, nil
+}
+
+// NewECDSAVerifier returns ECDSA without private key, which can only verify signatures
+func NewECDSAVerifier(a Algorithm, publicKey *ecdsa.PublicKey) (ECDSA, error) {
+	if publicKey == nil {
 		return ECDSA{}, ErrNilKey
 	}
 
 	var hash crypto.Hash
-	var keySize int
+	var curve elliptic.Curve
 
 	switch a {
 	case ES256:
-		hash, keySize = crypto.SHA256, 64
+		hash, curve = crypto.SHA256, elliptic.P256()
 	case ES384:
-		hash, keySize = crypto.SHA384, 96
+		hash, curve = crypto.SHA384, elliptic.P384()
 	case ES512:
-		hash, keySize = crypto.SHA512, 132
+		hash, curve = crypto.SHA512, elliptic.P521()
 	default:
 		return ECDSA{}, fmt.Errorf("algorithm %s is not ECDSA", a)
 	}
 
+	if publicKey.Curve == nil {
+		return ECDSA{}, fmt.Errorf("%w: public key has no curve", ErrNilKey)
+	}
+
 	size := roundToBytes(publicKey.Params().BitSize) * 2
-	if size != keySize {
-		return ECDSA{}, fmt.Errorf("incorrect key size: %d", size)
+	if publicKey.Curve != curve {
+		return ECDSA{}, fmt.Errorf("%w: incorrect key curve %s for %s, key size: %d", ErrKeyMismatch, publicKey.Params().Name, a, size)
+	}
+	if publicKey.X == nil || publicKey.Y == nil || !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return ECDSA{}, fmt.Errorf("%w: public key is not on curve %s", ErrKeyMismatch, curve.Params().Name)
 	}
 
 	return ECDSA{
-		privateKey: privateKey,
-		publicKey:  publicKey,
-		pool:       NewHashPool(hash.New),
-		size:       size,
+		publicKey: publicKey,
+		pool:      NewHashPool(hash.New),
+		size:      size,
 	}, nil
 }
 
@@ -74,6 +101,10 @@ func (e ECDSA) Size() int {
 }
 
 func (e ECDSA) Sign(payload []byte) ([]byte, error) {
+	if e.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
 	digest, err := e.pool.Digest(payload)
 	if err != nil {
 		return nil, err
@@ -105,3 +136,27 @@ func roundToBytes(bits int) int {
 
 	return bytes
 }
+
+// JWK returns JWK with the public key
+func (e ECDSA) JWK() (JWK, error) {
+	return NewJWK(e.publicKey)
+}
+
+// checkECDSAKeyPair derives public point of privateKey scalar, as the embedded public key may not match it
+func checkECDSAKeyPair(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) error {
+	if !publicKey.Equal(&privateKey.PublicKey) {
+		return ErrKeyMismatch
+	}
+
+	d := privateKey.D
+	if d == nil || d.Sign() <= 0 || d.Cmp(publicKey.Curve.Params().N) >= 0 {
+		return fmt.Errorf("%w: private scalar is out of range", ErrKeyMismatch)
+	}
+
+	x, y := publicKey.Curve.ScalarBaseMult(d.Bytes())
+	if x.Cmp(publicKey.X) != 0 || y.Cmp(publicKey.Y) != 0 {
+		return ErrKeyMismatch
+	}
+
+	return nil
+}
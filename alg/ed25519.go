@@ -1,6 +1,9 @@
 package alg
 
-import "crypto/ed25519"
+import (
+	"crypto/ed25519"
+	"fmt"
+)
 
 type Ed25519 struct {
 	public  ed25519.PublicKey
@@ -11,18 +14,46 @@ func NewEd25519(private ed25519.PrivateKey, public ed25519.PublicKey) (Ed25519,
 	if len(public) == 0 || len(private) == 0 {
 		return Ed25519{}, ErrNilKey
 	}
+	if len(public) != ed25519.PublicKeySize || len(private) != ed25519.PrivateKeySize {
+		return Ed25519{}, ErrKeyMismatch
+	}
+	// the public half of private is used for signing, so it is checked against one derived from the seed
+	if !public.Equal(private.Public()) || !private.Equal(ed25519.NewKeyFromSeed(private.Seed())) {
+		return Ed25519{}, ErrKeyMismatch
+	}
 
 	return Ed25519{public: public, private: private}, nil
 }
 
+// NewEd25519Verifier returns Ed25519 without private key, which can only verify signatures
+func NewEd25519Verifier(public ed25519.PublicKey) (Ed25519, error) {
+	if len(public) == 0 {
+		return Ed25519{}, ErrNilKey
+	}
+	if len(public) != ed25519.PublicKeySize {
+		return Ed25519{}, fmt.Errorf("incorrect Ed25519 public key size: %d", len(public))
+	}
+
+	return Ed25519{public: public}, nil
+}
+
 func (e Ed25519) Size() int {
 	return ed25519.SignatureSize
 }
 
 func (e Ed25519) Sign(payload []byte) ([]byte, error) {
+	if len(e.private) == 0 {
+		return nil, ErrNoPrivateKey
+	}
+
 	return ed25519.Sign(e.private, payload), nil
 }
 
 func (e Ed25519) Verify(payload, signature []byte) (bool, error) {
 	return ed25519.Verify(e.public, payload, signature), nil
 }
+
+// JWK returns JWK with the public key
+func (e Ed25519) JWK() (JWK, error) {
+	return NewJWK(e.public)
+}
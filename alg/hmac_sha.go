@@ -13,21 +13,19 @@ type HmacSha struct {
 	hash crypto.Hash
 }
 
-func NewHmacSha(a Algorithm, key string) (HmacSha, error) {
+// NewHmacSha returns HMAC SHA algorithm a with secret key.
+// Key must be at least as long as the hash output (RFC 7518, section 3.2) unless AllowWeakKeys is used
+func NewHmacSha(a Algorithm, key string, options ...KeyOption) (HmacSha, error) {
 	if len(key) == 0 {
 		return HmacSha{}, ErrNilKey
 	}
 
-	var hashFunc crypto.Hash
-	switch a {
-	case HS256:
-		hashFunc = crypto.SHA256
-	case HS384:
-		hashFunc = crypto.SHA384
-	case HS512:
-		hashFunc = crypto.SHA512
-	default:
-		return HmacSha{}, fmt.Errorf("algorithm %s is not HMAC SHA", a)
+	hashFunc, err := hmacHash(a)
+	if err != nil {
+		return HmacSha{}, err
+	}
+	if len(key) < hashFunc.Size() && !newKeyOptions(options).allowWeak {
+		return HmacSha{}, fmt.Errorf("%w: %s requires at least %d bytes secret", ErrWeakKey, a, hashFunc.Size())
 	}
 
 	return HmacSha{
@@ -38,6 +36,19 @@ func NewHmacSha(a Algorithm, key string) (HmacSha, error) {
 	}, nil
 }
 
+func hmacHash(a Algorithm) (crypto.Hash, error) {
+	switch a {
+	case HS256:
+		return crypto.SHA256, nil
+	case HS384:
+		return crypto.SHA384, nil
+	case HS512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("algorithm %s is not HMAC SHA", a)
+	}
+}
+
 func (h HmacSha) Verify(payload, signature []byte) (bool, error) {
 	expected, err := h.Sign(payload)
 	if err != nil {
@@ -24,7 +24,7 @@ func testHmacSha(a Algorithm, key, payload, signature string) func(t *testing.T)
 
 		payloadBytes := []byte(payload)
 
-		hs256, err := NewHmacSha(a, key)
+		hs256, err := NewHmacSha(a, key, AllowWeakKeys())
 		require.NoError(t, err)
 
 		signatureBytes, err := hs256.Sign(payloadBytes)
@@ -39,7 +39,7 @@ func testHmacSha(a Algorithm, key, payload, signature string) func(t *testing.T)
 
 func TestHS(t *testing.T) {
 	t.Run("size", func(t *testing.T) {
-		hs256, err := NewHmacSha(HS256, "key")
+		hs256, err := NewHmacSha(HS256, "key", AllowWeakKeys())
 		require.NoError(t, err)
 
 		assert.Equal(t, crypto.SHA256.Size(), hs256.Size())
@@ -58,10 +58,10 @@ func TestHS(t *testing.T) {
 		require.Error(t, err)
 	})
 	t.Run("incorrect key", func(t *testing.T) {
-		primary, err := NewHmacSha(HS256, "primary")
+		primary, err := NewHmacSha(HS256, "primary", AllowWeakKeys())
 		require.NoError(t, err)
 
-		secondary, err := NewHmacSha(HS256, "secondary")
+		secondary, err := NewHmacSha(HS256, "secondary", AllowWeakKeys())
 		require.NoError(t, err)
 
 		payload := []byte("message")
@@ -73,7 +73,7 @@ func TestHS(t *testing.T) {
 		require.False(t, ok)
 	})
 	t.Run("error digest", func(t *testing.T) {
-		hs, err := NewHmacSha(HS256, "test")
+		hs, err := NewHmacSha(HS256, "test", AllowWeakKeys())
 		require.NoError(t, err)
 		hs.pool = NewHashPool(func() hash.Hash {
 			return &errorHash{}
@@ -88,6 +88,6 @@ func TestHS(t *testing.T) {
 }
 
 func TestHmacSha_InvalidAlg(t *testing.T) {
-	_, err := NewHmacSha(RS256, "test")
+	_, err := NewHmacSha(RS256, "test", AllowWeakKeys())
 	require.Error(t, err)
 }
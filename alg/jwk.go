@@ -0,0 +1,479 @@
+package alg
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	KeyTypeEC  = "EC"
+	KeyTypeRSA = "RSA"
+	KeyTypeOKP = "OKP"
+	KeyTypeOct = "oct"
+
+	CurveP256    = "P-256"
+	CurveP384    = "P-384"
+	CurveP521    = "P-521"
+	CurveEd25519 = "Ed25519"
+
+	// ThumbprintURIPrefix is the URN prefix of JWK thumbprint URIs, RFC 9278
+	ThumbprintURIPrefix = "urn:ietf:params:oauth:jwk-thumbprint:"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrBadJWK         = errors.New("bad jwk")
+	ErrBadThumbprint  = errors.New("bad jwk thumbprint uri")
+
+	thumbprintHashes = map[string]crypto.Hash{
+		"sha-256": crypto.SHA256,
+		"sha-384": crypto.SHA384,
+		"sha-512": crypto.SHA512,
+	}
+)
+
+// JWK is JSON Web Key, RFC 7517
+type JWK struct {
+	KeyType   string    `json:"kty"`
+	Use       string    `json:"use,omitempty"`
+	Algorithm Algorithm `json:"alg,omitempty"`
+	KeyId     string    `json:"kid,omitempty"`
+
+	// Curve is used by EC and OKP keys
+	Curve string `json:"crv,omitempty"`
+	// X is used by EC and OKP keys
+	X string `json:"x,omitempty"`
+	// Y is used by EC keys
+	Y string `json:"y,omitempty"`
+
+	// N is RSA modulus
+	N string `json:"n,omitempty"`
+	// E is RSA public exponent
+	E string `json:"e,omitempty"`
+
+	// K is symmetric key value
+	K string `json:"k,omitempty"`
+
+	// D is private exponent of RSA key or private key of EC and OKP keys
+	D string `json:"d,omitempty"`
+	// P is RSA first prime factor
+	P string `json:"p,omitempty"`
+	// Q is RSA second prime factor
+	Q string `json:"q,omitempty"`
+	// DP is RSA first factor CRT exponent
+	DP string `json:"dp,omitempty"`
+	// DQ is RSA second factor CRT exponent
+	DQ string `json:"dq,omitempty"`
+	// QI is RSA first CRT coefficient
+	QI string `json:"qi,omitempty"`
+}
+
+// JWKSet is JSON Web Key Set, RFC 7517
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Find returns key with specified "kid"
+func (s JWKSet) Find(keyId string) (JWK, bool) {
+	for _, key := range s.Keys {
+		if key.KeyId == keyId {
+			return key, true
+		}
+	}
+
+	return JWK{}, false
+}
+
+// JWKProvider is implemented by asymmetric algorithms which can describe their public key as JWK.
+// HmacSha does not implement it, so the secret is never exposed
+type JWKProvider interface {
+	JWK() (JWK, error)
+}
+
+// NewJWK returns JWK describing public key (*rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey),
+// private key (*rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey) or symmetric secret ([]byte)
+func NewJWK(key interface{}) (JWK, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k == nil {
+			return JWK{}, ErrNilKey
+		}
+		if len(k.Primes) != 2 {
+			return JWK{}, fmt.Errorf("%w: only two-prime RSA keys supported", ErrUnsupportedKey)
+		}
+
+		jwk, err := NewJWK(&k.PublicKey)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		// CRT values are computed here instead of k.Precompute(), which would modify the caller's key
+		p, q := k.Primes[0], k.Primes[1]
+		one := big.NewInt(1)
+		dp := new(big.Int).Mod(k.D, new(big.Int).Sub(p, one))
+		dq := new(big.Int).Mod(k.D, new(big.Int).Sub(q, one))
+		qi := new(big.Int).ModInverse(q, p)
+		if qi == nil {
+			return JWK{}, fmt.Errorf("%w: RSA primes are not coprime", ErrUnsupportedKey)
+		}
+
+		jwk.D = encodeJWKBytes(k.D.Bytes())
+		jwk.P = encodeJWKBytes(p.Bytes())
+		jwk.Q = encodeJWKBytes(q.Bytes())
+		jwk.DP = encodeJWKBytes(dp.Bytes())
+		jwk.DQ = encodeJWKBytes(dq.Bytes())
+		jwk.QI = encodeJWKBytes(qi.Bytes())
+		return jwk, nil
+	case *ecdsa.PrivateKey:
+		if k == nil {
+			return JWK{}, ErrNilKey
+		}
+
+		jwk, err := NewJWK(&k.PublicKey)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		jwk.D = encodeJWKBytes(k.D.FillBytes(make([]byte, roundToBytes(k.Params().BitSize))))
+		return jwk, nil
+	case ed25519.PrivateKey:
+		if len(k) == 0 {
+			return JWK{}, ErrNilKey
+		}
+		if len(k) != ed25519.PrivateKeySize {
+			return JWK{}, fmt.Errorf("%w: Ed25519 private key has %d bytes", ErrUnsupportedKey, len(k))
+		}
+
+		jwk, err := NewJWK(k.Public())
+		if err != nil {
+			return JWK{}, err
+		}
+
+		jwk.D = encodeJWKBytes(k.Seed())
+		return jwk, nil
+	case *rsa.PublicKey:
+		if k == nil {
+			return JWK{}, ErrNilKey
+		}
+
+		return JWK{
+			KeyType: KeyTypeRSA,
+			N:       encodeJWKBytes(k.N.Bytes()),
+			E:       encodeJWKBytes(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if k == nil {
+			return JWK{}, ErrNilKey
+		}
+
+		curve, err := curveName(k.Curve)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		size := roundToBytes(k.Params().BitSize)
+		return JWK{
+			KeyType: KeyTypeEC,
+			Curve:   curve,
+			X:       encodeJWKBytes(k.X.FillBytes(make([]byte, size))),
+			Y:       encodeJWKBytes(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return JWK{}, ErrNilKey
+		}
+
+		return JWK{
+			KeyType: KeyTypeOKP,
+			Curve:   CurveEd25519,
+			X:       encodeJWKBytes(k),
+		}, nil
+	case []byte:
+		if len(k) == 0 {
+			return JWK{}, ErrNilKey
+		}
+
+		return JWK{
+			KeyType: KeyTypeOct,
+			K:       encodeJWKBytes(k),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
+
+// IsPrivate reports whether JWK contains private or symmetric key
+func (j JWK) IsPrivate() bool {
+	return len(j.D) > 0 || len(j.K) > 0
+}
+
+// Public returns copy of JWK without private members, symmetric keys are returned as is
+func (j JWK) Public() JWK {
+	j.D, j.P, j.Q, j.DP, j.DQ, j.QI = "", "", "", "", "", ""
+	return j
+}
+
+// PrivateKey decodes private key of asymmetric JWK or secret of symmetric one ([]byte)
+func (j JWK) PrivateKey() (crypto.PrivateKey, error) {
+	if j.KeyType == KeyTypeOct {
+		return decodeJWKBytes("k", j.K)
+	}
+
+	public, err := j.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeJWKBytes("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return j.rsaPrivateKey(key, d)
+	case *ecdsa.PublicKey:
+		private := &ecdsa.PrivateKey{PublicKey: *key, D: new(big.Int).SetBytes(d)}
+		x, y := key.Curve.ScalarBaseMult(d)
+		if x.Cmp(key.X) != 0 || y.Cmp(key.Y) != 0 {
+			return nil, fmt.Errorf("%w: d does not match public key", ErrBadJWK)
+		}
+
+		return private, nil
+	case ed25519.PublicKey:
+		if len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("%w: incorrect Ed25519 seed size", ErrBadJWK)
+		}
+
+		private := ed25519.NewKeyFromSeed(d)
+		if !key.Equal(private.Public()) {
+			return nil, fmt.Errorf("%w: d does not match public key", ErrBadJWK)
+		}
+
+		return private, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, public)
+	}
+}
+
+func (j JWK) rsaPrivateKey(public *rsa.PublicKey, d []byte) (*rsa.PrivateKey, error) {
+	p, err := decodeJWKBytes("p", j.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeJWKBytes("q", j.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	private := &rsa.PrivateKey{
+		PublicKey: *public,
+		D:         new(big.Int).SetBytes(d),
+		Primes:    []*big.Int{new(big.Int).SetBytes(p), new(big.Int).SetBytes(q)},
+	}
+	if err = private.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadJWK, err)
+	}
+
+	private.Precompute()
+	return private, nil
+}
+
+// PublicKey decodes public key of asymmetric JWK
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case KeyTypeRSA:
+		n, err := decodeJWKBytes("n", j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKBytes("e", j.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) > 4 {
+			return nil, fmt.Errorf("%w: e is too large", ErrBadJWK)
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case KeyTypeEC:
+		curve, err := curveByName(j.Curve)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeJWKBytes("x", j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKBytes("y", j.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("%w: point is not on curve %s", ErrBadJWK, j.Curve)
+		}
+
+		return key, nil
+	case KeyTypeOKP:
+		if j.Curve != CurveEd25519 {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, j.Curve)
+		}
+		x, err := decodeJWKBytes("x", j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: incorrect Ed25519 key size", ErrBadJWK)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, j.KeyType)
+	}
+}
+
+// Thumbprint returns SHA-256 JWK thumbprint, RFC 7638
+func (j JWK) Thumbprint() ([]byte, error) {
+	return j.ThumbprintHash(crypto.SHA256)
+}
+
+// ThumbprintHash returns JWK thumbprint computed with hash, RFC 7638
+func (j JWK) ThumbprintHash(hash crypto.Hash) ([]byte, error) {
+	var members []string
+	switch j.KeyType {
+	case KeyTypeEC:
+		members = []string{"crv", j.Curve, "kty", j.KeyType, "x", j.X, "y", j.Y}
+	case KeyTypeRSA:
+		members = []string{"e", j.E, "kty", j.KeyType, "n", j.N}
+	case KeyTypeOKP:
+		members = []string{"crv", j.Curve, "kty", j.KeyType, "x", j.X}
+	case KeyTypeOct:
+		members = []string{"k", j.K, "kty", j.KeyType}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, j.KeyType)
+	}
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash %v is not available", hash)
+	}
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i := 0; i < len(members); i += 2 {
+		if len(members[i+1]) == 0 {
+			return nil, fmt.Errorf("%w: %s is required", ErrBadJWK, members[i])
+		}
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+
+		value, _ := json.Marshal(members[i+1])
+		builder.WriteString(`"` + members[i] + `":`)
+		builder.Write(value)
+	}
+	builder.WriteByte('}')
+
+	hasher := hash.New()
+	_, _ = hasher.Write([]byte(builder.String()))
+	return hasher.Sum(nil), nil
+}
+
+// ThumbprintURI returns SHA-256 JWK thumbprint URI, RFC 9278
+func (j JWK) ThumbprintURI() (string, error) {
+	thumbprint, err := j.Thumbprint()
+	if err != nil {
+		return "", err
+	}
+
+	return FormatThumbprintURI(crypto.SHA256, thumbprint)
+}
+
+// FormatThumbprintURI formats thumbprint as "urn:ietf:params:oauth:jwk-thumbprint:<hash>:<value>"
+func FormatThumbprintURI(hash crypto.Hash, thumbprint []byte) (string, error) {
+	for name, h := range thumbprintHashes {
+		if h == hash {
+			return ThumbprintURIPrefix + name + ":" + encodeJWKBytes(thumbprint), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unsupported hash %v", ErrBadThumbprint, hash)
+}
+
+// ParseThumbprintURI returns hash and thumbprint from JWK thumbprint URI
+func ParseThumbprintURI(uri string) (crypto.Hash, []byte, error) {
+	if !strings.HasPrefix(uri, ThumbprintURIPrefix) {
+		return 0, nil, ErrBadThumbprint
+	}
+
+	parts := strings.Split(uri[len(ThumbprintURIPrefix):], ":")
+	if len(parts) != 2 {
+		return 0, nil, ErrBadThumbprint
+	}
+
+	hash, ok := thumbprintHashes[strings.ToLower(parts[0])]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: unsupported hash %s", ErrBadThumbprint, parts[0])
+	}
+
+	thumbprint, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(thumbprint) != hash.Size() {
+		return 0, nil, fmt.Errorf("%w: incorrect value", ErrBadThumbprint)
+	}
+
+	return hash, thumbprint, nil
+}
+
+func curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return CurveP256, nil
+	case elliptic.P384():
+		return CurveP384, nil
+	case elliptic.P521():
+		return CurveP521, nil
+	default:
+		return "", fmt.Errorf("%w: curve %s", ErrUnsupportedKey, curve.Params().Name)
+	}
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case CurveP256:
+		return elliptic.P256(), nil
+	case CurveP384:
+		return elliptic.P384(), nil
+	case CurveP521:
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, name)
+	}
+}
+
+func encodeJWKBytes(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeJWKBytes(name, value string) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, fmt.Errorf("%w: %s is required", ErrBadJWK, name)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrBadJWK, name, err)
+	}
+
+	return data, nil
+}
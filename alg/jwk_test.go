@@ -0,0 +1,151 @@
+package alg
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWK_Thumbprint(t *testing.T) {
+	t.Run("rfc 7638 example", func(t *testing.T) {
+		jwk := JWK{
+			KeyType:   KeyTypeRSA,
+			N:         "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+			E:         "AQAB",
+			Algorithm: RS256,
+			KeyId:     "2011-04-29",
+		}
+
+		thumbprint, err := jwk.Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", toBase64(thumbprint))
+
+		uri, err := jwk.ThumbprintURI()
+		require.NoError(t, err)
+		assert.Equal(t, "urn:ietf:params:oauth:jwk-thumbprint:sha-256:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", uri)
+	})
+	t.Run("all key types", func(t *testing.T) {
+		keys := []interface{}{rsa256PublicKey, ecdsa256PublicKey, ecdsa521PublicKey, ed25519.PublicKey(ed25519PublicKey), []byte("secret")}
+		for _, key := range keys {
+			jwk, err := NewJWK(key)
+			require.NoError(t, err)
+
+			thumbprint, err := jwk.Thumbprint()
+			require.NoError(t, err)
+			assert.Len(t, thumbprint, crypto.SHA256.Size())
+
+			thumbprint512, err := jwk.ThumbprintHash(crypto.SHA512)
+			require.NoError(t, err)
+			assert.Len(t, thumbprint512, crypto.SHA512.Size())
+
+			if jwk.KeyType == KeyTypeOct {
+				continue
+			}
+
+			public, err := jwk.PublicKey()
+			require.NoError(t, err)
+			assert.Equal(t, key, public)
+		}
+	})
+	t.Run("optional members are ignored", func(t *testing.T) {
+		jwk, err := NewJWK(ecdsa256PublicKey)
+		require.NoError(t, err)
+		expected, err := jwk.Thumbprint()
+		require.NoError(t, err)
+
+		jwk.KeyId, jwk.Use, jwk.Algorithm = "key", "sig", ES256
+		actual, err := jwk.Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("bad", func(t *testing.T) {
+		_, err := JWK{KeyType: "unknown"}.Thumbprint()
+		require.ErrorIs(t, err, ErrUnsupportedKey)
+
+		_, err = JWK{KeyType: KeyTypeEC, Curve: CurveP256, X: "AA"}.Thumbprint()
+		require.ErrorIs(t, err, ErrBadJWK)
+	})
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	var jwk JWK
+	require.NoError(t, json.Unmarshal([]byte(`{"kty":"EC","crv":"P-256","x":"AAAA","y":"AAAA"}`), &jwk))
+	_, err := jwk.PublicKey()
+	require.ErrorIs(t, err, ErrBadJWK)
+
+	_, err = JWK{KeyType: KeyTypeEC, Curve: "P-192", X: "AA", Y: "AA"}.PublicKey()
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+
+	_, err = JWK{KeyType: KeyTypeOKP, Curve: CurveEd25519, X: "AAAA"}.PublicKey()
+	require.ErrorIs(t, err, ErrBadJWK)
+
+	_, err = JWK{KeyType: KeyTypeRSA, N: "AQAB"}.PublicKey()
+	require.ErrorIs(t, err, ErrBadJWK)
+
+	_, err = JWK{KeyType: KeyTypeOct, K: "AQAB"}.PublicKey()
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestThumbprintURI(t *testing.T) {
+	thumbprint, err := fromBase64("NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs")
+	require.NoError(t, err)
+
+	uri, err := FormatThumbprintURI(crypto.SHA256, thumbprint)
+	require.NoError(t, err)
+
+	hash, parsed, err := ParseThumbprintURI(uri)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SHA256, hash)
+	assert.Equal(t, thumbprint, parsed)
+
+	_, err = FormatThumbprintURI(crypto.MD5, thumbprint)
+	require.ErrorIs(t, err, ErrBadThumbprint)
+
+	for _, bad := range []string{
+		"urn:ietf:params:oauth:jwk-thumbprint:sha-256",
+		"urn:ietf:params:oauth:jwk-thumbprint:md5:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		"urn:ietf:params:oauth:jwk-thumbprint:sha-256:AAAA",
+		"urn:example:sha-256:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+	} {
+		_, _, err = ParseThumbprintURI(bad)
+		require.ErrorIs(t, err, ErrBadThumbprint, bad)
+	}
+}
+
+func TestAlgorithm_JWK(t *testing.T) {
+	es, err := NewECDSA(ES256, ecdsa256PrivateKey, ecdsa256PublicKey)
+	require.NoError(t, err)
+	rs, err := NewRsaSsaPkcs1(RS256, rsa256PrivateKey, rsa256PublicKey, AllowWeakKeys())
+	require.NoError(t, err)
+	ps, err := NewRsaSsaPss(PS256, rsa256PrivateKey, rsa256PublicKey, AllowWeakKeys())
+	require.NoError(t, err)
+	ed, err := NewEd25519(ed25519PrivateKey, ed25519PublicKey)
+	require.NoError(t, err)
+
+	expected := []string{KeyTypeEC, KeyTypeRSA, KeyTypeRSA, KeyTypeOKP}
+	for i, provider := range []JWKProvider{es, rs, ps, ed} {
+		jwk, err := provider.JWK()
+		require.NoError(t, err)
+		assert.Equal(t, expected[i], jwk.KeyType)
+	}
+
+	hs, err := NewHmacSha(HS256, "secret", AllowWeakKeys())
+	require.NoError(t, err)
+	_, ok := interface{}(hs).(JWKProvider)
+	assert.False(t, ok)
+}
+
+func TestJWKSet_Find(t *testing.T) {
+	set := JWKSet{Keys: []JWK{{KeyType: KeyTypeOct, KeyId: "first"}, {KeyType: KeyTypeEC, KeyId: "second"}}}
+
+	key, ok := set.Find("second")
+	require.True(t, ok)
+	assert.Equal(t, KeyTypeEC, key.KeyType)
+
+	_, ok = set.Find("third")
+	assert.False(t, ok)
+}
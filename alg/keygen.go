@@ -0,0 +1,192 @@
+package alg
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	// MinRsaKeySize is the minimal RSA key size in bits allowed by RFC 7518
+	MinRsaKeySize = 2048
+)
+
+// GeneratedKey contains newly generated key and algorithm implementation using it
+type GeneratedKey struct {
+	Algorithm Algorithm
+	// PrivateKey is *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or []byte secret for HMAC
+	PrivateKey crypto.PrivateKey
+	// PublicKey is nil for HMAC
+	PublicKey crypto.PublicKey
+	Signer    Signer
+	Verifier  Verifier
+}
+
+// GenerateKey generates key appropriate for algorithm a: HMAC secret of hash size,
+// 2048 bits RSA key, ECDSA key on the algorithm curve or Ed25519 key
+func GenerateKey(a Algorithm) (GeneratedKey, error) {
+	switch a {
+	case HS256, HS384, HS512:
+		return GenerateHmacKey(a, 0)
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		return GenerateRsaKey(a, MinRsaKeySize)
+	case ES256, ES384, ES512:
+		return generateECDSAKey(a)
+	case EdDSA:
+		return generateEd25519Key()
+	default:
+		return GeneratedKey{}, fmt.Errorf("key generation for algorithm %s is not supported", a)
+	}
+}
+
+// GenerateHmacKey generates random secret of size bytes for HMAC algorithm a.
+// Size less than hash output size is replaced with hash output size
+func GenerateHmacKey(a Algorithm, size int) (GeneratedKey, error) {
+	hash, err := hmacHash(a)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+	if size < hash.Size() {
+		size = hash.Size()
+	}
+
+	secret := make([]byte, size)
+	if _, err = rand.Read(secret); err != nil {
+		return GeneratedKey{}, err
+	}
+
+	hs, err := NewHmacSha(a, string(secret))
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	return GeneratedKey{Algorithm: a, PrivateKey: secret, Signer: hs, Verifier: hs}, nil
+}
+
+// GenerateRsaKey generates RSA key of bits size for RS* or PS* algorithm a
+func GenerateRsaKey(a Algorithm, bits int) (GeneratedKey, error) {
+	if bits < MinRsaKeySize {
+		return GeneratedKey{}, fmt.Errorf("RSA key size should be at least %d bits", MinRsaKeySize)
+	}
+
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	key := GeneratedKey{Algorithm: a, PrivateKey: private, PublicKey: &private.PublicKey}
+	switch a {
+	case RS256, RS384, RS512:
+		rs, err := NewRsaSsaPkcs1(a, private, &private.PublicKey)
+		if err != nil {
+			return GeneratedKey{}, err
+		}
+		key.Signer, key.Verifier = rs, rs
+	default:
+		ps, err := NewRsaSsaPss(a, private, &private.PublicKey)
+		if err != nil {
+			return GeneratedKey{}, err
+		}
+		key.Signer, key.Verifier = ps, ps
+	}
+
+	return key, nil
+}
+
+func generateECDSAKey(a Algorithm) (GeneratedKey, error) {
+	var curve elliptic.Curve
+	switch a {
+	case ES256:
+		curve = elliptic.P256()
+	case ES384:
+		curve = elliptic.P384()
+	default:
+		curve = elliptic.P521()
+	}
+
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	es, err := NewECDSA(a, private, &private.PublicKey)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	return GeneratedKey{Algorithm: a, PrivateKey: private, PublicKey: &private.PublicKey, Signer: es, Verifier: es}, nil
+}
+
+func generateEd25519Key() (GeneratedKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	ed, err := NewEd25519(private, public)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	return GeneratedKey{Algorithm: EdDSA, PrivateKey: private, PublicKey: public, Signer: ed, Verifier: ed}, nil
+}
+
+// JWK returns JWK with private (or symmetric) key, "alg" is set
+func (k GeneratedKey) JWK() (JWK, error) {
+	jwk, err := NewJWK(k.PrivateKey)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	jwk.Algorithm = k.Algorithm
+	jwk.Use = "sig"
+	return jwk, nil
+}
+
+// PublicJWK returns JWK with public key, "alg" is set. Symmetric keys have no public JWK
+func (k GeneratedKey) PublicJWK() (JWK, error) {
+	if k.PublicKey == nil {
+		return JWK{}, fmt.Errorf("%w: symmetric key has no public part", ErrUnsupportedKey)
+	}
+
+	jwk, err := k.JWK()
+	if err != nil {
+		return JWK{}, err
+	}
+
+	return jwk.Public(), nil
+}
+
+// PrivatePEM returns PKCS #8 "PRIVATE KEY" PEM block
+func (k GeneratedKey) PrivatePEM() ([]byte, error) {
+	if k.PublicKey == nil {
+		return nil, fmt.Errorf("%w: symmetric key has no PEM encoding", ErrUnsupportedKey)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicPEM returns PKIX "PUBLIC KEY" PEM block
+func (k GeneratedKey) PublicPEM() ([]byte, error) {
+	if k.PublicKey == nil {
+		return nil, fmt.Errorf("%w: symmetric key has no PEM encoding", ErrUnsupportedKey)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
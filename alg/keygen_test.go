@@ -0,0 +1,141 @@
+package alg
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKey(t *testing.T) {
+	algorithms := []Algorithm{HS256, HS384, HS512, RS256, PS384, ES256, ES384, ES512, EdDSA}
+	for _, a := range algorithms {
+		a := a
+		t.Run(string(a), func(t *testing.T) {
+			key, err := GenerateKey(a)
+			require.NoError(t, err)
+			assert.Equal(t, a, key.Algorithm)
+
+			payload := []byte("freshly generated")
+			signature, err := key.Signer.Sign(payload)
+			require.NoError(t, err)
+
+			ok, err := key.Verifier.Verify(payload, signature)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			jwk, err := key.JWK()
+			require.NoError(t, err)
+			assert.Equal(t, a, jwk.Algorithm)
+			assert.True(t, jwk.IsPrivate())
+
+			data, err := json.Marshal(jwk)
+			require.NoError(t, err)
+
+			var decoded JWK
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			private, err := decoded.PrivateKey()
+			require.NoError(t, err)
+			assert.Equal(t, key.PrivateKey, private)
+
+			if key.PublicKey == nil {
+				_, err = key.PublicJWK()
+				require.ErrorIs(t, err, ErrUnsupportedKey)
+				_, err = key.PrivatePEM()
+				require.ErrorIs(t, err, ErrUnsupportedKey)
+				_, err = key.PublicPEM()
+				require.ErrorIs(t, err, ErrUnsupportedKey)
+				return
+			}
+
+			publicJwk, err := key.PublicJWK()
+			require.NoError(t, err)
+			assert.False(t, publicJwk.IsPrivate())
+
+			privatePem, err := key.PrivatePEM()
+			require.NoError(t, err)
+			block, _ := pem.Decode(privatePem)
+			require.NotNil(t, block)
+			_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			require.NoError(t, err)
+
+			publicPem, err := key.PublicPEM()
+			require.NoError(t, err)
+			block, _ = pem.Decode(publicPem)
+			require.NotNil(t, block)
+			public, err := x509.ParsePKIXPublicKey(block.Bytes)
+			require.NoError(t, err)
+			assert.Equal(t, key.PublicKey, public)
+		})
+	}
+}
+
+func TestGenerateKey_Sizes(t *testing.T) {
+	key, err := GenerateHmacKey(HS512, 16)
+	require.NoError(t, err)
+	assert.Len(t, key.PrivateKey, 64)
+
+	key, err = GenerateHmacKey(HS256, 100)
+	require.NoError(t, err)
+	assert.Len(t, key.PrivateKey, 100)
+
+	_, err = GenerateRsaKey(RS256, 1024)
+	require.Error(t, err)
+
+	_, err = GenerateKey(None)
+	require.Error(t, err)
+
+	_, err = GenerateHmacKey(RS256, 32)
+	require.Error(t, err)
+}
+
+func TestJWK_PrivateKey(t *testing.T) {
+	t.Run("public part", func(t *testing.T) {
+		jwk, err := NewJWK(ecdsa256PrivateKey)
+		require.NoError(t, err)
+
+		public, err := NewJWK(ecdsa256PublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, public, jwk.Public())
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		jwk, err := NewJWK(ecdsa256PrivateKey)
+		require.NoError(t, err)
+
+		alternative, err := NewJWK(ecdsa256PrivateKeyAlternative)
+		require.NoError(t, err)
+
+		jwk.D = alternative.D
+		_, err = jwk.PrivateKey()
+		require.ErrorIs(t, err, ErrBadJWK)
+	})
+	t.Run("rsa key is not modified", func(t *testing.T) {
+		key := &rsa.PrivateKey{PublicKey: rsa384PrivateKey.PublicKey, D: rsa384PrivateKey.D, Primes: rsa384PrivateKey.Primes}
+		jwk, err := NewJWK(key)
+		require.NoError(t, err)
+		assert.Nil(t, key.Precomputed.Dp)
+
+		precomputed, err := NewJWK(rsa384PrivateKey)
+		require.NoError(t, err)
+		assert.Equal(t, precomputed, jwk)
+	})
+	t.Run("ed25519 size", func(t *testing.T) {
+		_, err := NewJWK(ed25519.PrivateKey(ed25519PrivateKey[:10]))
+		require.ErrorIs(t, err, ErrUnsupportedKey)
+
+		_, err = NewJWK(ed25519.PrivateKey{})
+		require.ErrorIs(t, err, ErrNilKey)
+	})
+	t.Run("public only", func(t *testing.T) {
+		jwk, err := NewJWK(rsa384PublicKey)
+		require.NoError(t, err)
+
+		_, err = jwk.PrivateKey()
+		require.ErrorIs(t, err, ErrBadJWK)
+	})
+}
@@ -0,0 +1,32 @@
+package alg
+
+import "errors"
+
+var (
+	ErrWeakKey     = errors.New("key is too weak for the algorithm")
+	ErrKeyMismatch = errors.New("private and public keys do not form a pair")
+)
+
+// KeyOption configures key checks performed by algorithm constructors
+type KeyOption func(o *keyOptions)
+
+type keyOptions struct {
+	allowWeak bool
+}
+
+// AllowWeakKeys disables RFC 7518 minimal key size checks.
+// Use it only for legacy keys which can not be rotated, key pair consistency is still checked
+func AllowWeakKeys() KeyOption {
+	return func(o *keyOptions) {
+		o.allowWeak = true
+	}
+}
+
+func newKeyOptions(options []KeyOption) keyOptions {
+	o := keyOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,107 @@
+package alg
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStrength(t *testing.T) {
+	t.Run("hmac", func(t *testing.T) {
+		_, err := NewHmacSha(HS256, "short")
+		require.ErrorIs(t, err, ErrWeakKey)
+
+		_, err = NewHmacSha(HS512, "0123456789abcdef0123456789abcdef")
+		require.ErrorIs(t, err, ErrWeakKey)
+
+		_, err = NewHmacSha(HS256, "0123456789abcdef0123456789abcdef")
+		require.NoError(t, err)
+
+		_, err = NewHmacSha(HS256, "short", AllowWeakKeys())
+		require.NoError(t, err)
+	})
+	t.Run("rsa", func(t *testing.T) {
+		_, err := NewRsaSsaPkcs1(RS256, rsa256PrivateKey, rsa256PublicKey)
+		require.ErrorIs(t, err, ErrWeakKey)
+
+		_, err = NewRsaSsaPss(PS256, rsa256PrivateKey, rsa256PublicKey)
+		require.ErrorIs(t, err, ErrWeakKey)
+
+		_, err = NewVerifier(RS256, rsa256PublicKey)
+		require.ErrorIs(t, err, ErrWeakKey)
+
+		_, err = NewVerifier(RS256, rsa256PublicKey, AllowWeakKeys())
+		require.NoError(t, err)
+
+		_, err = NewRsaSsaPkcs1(RS384, rsa384PrivateKey, rsa384PublicKey)
+		require.NoError(t, err)
+	})
+}
+
+func TestKeyConsistency(t *testing.T) {
+	t.Run("rsa", func(t *testing.T) {
+		_, err := NewRsaSsaPkcs1(RS256, rsa384PrivateKey, rsa512PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewRsaSsaPss(PS256, rsa512PrivateKey, rsa384PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewRsaSsaPkcs1(RS256, rsa256PrivateKey, rsa256PublicKeyAlternative, AllowWeakKeys())
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		badExponent := *rsa384PrivateKey
+		badExponent.D = new(big.Int).Add(rsa384PrivateKey.D, big.NewInt(2))
+		_, err = NewRsaSsaPkcs1(RS384, &badExponent, rsa384PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewRsaSsaPss(PS384, &badExponent, rsa384PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+	})
+	t.Run("ecdsa", func(t *testing.T) {
+		_, err := NewECDSA(ES256, ecdsa256PrivateKey, ecdsa256PublicKeyAlternative)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewECDSA(ES384, ecdsa256PrivateKey, ecdsa384PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		badScalar := *ecdsa256PrivateKey
+		badScalar.D = new(big.Int).Add(ecdsa256PrivateKey.D, big.NewInt(1))
+		_, err = NewECDSA(ES256, &badScalar, ecdsa256PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		badScalar.D = big.NewInt(0)
+		_, err = NewECDSA(ES256, &badScalar, ecdsa256PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		offCurve := &ecdsa.PublicKey{Curve: elliptic.P256(), X: big.NewInt(1), Y: big.NewInt(1)}
+		_, err = NewECDSAVerifier(ES256, offCurve)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewECDSAVerifier(ES256, ecdsa384PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewECDSAVerifier(ES256, &ecdsa.PublicKey{X: big.NewInt(1), Y: big.NewInt(1)})
+		require.ErrorIs(t, err, ErrNilKey)
+	})
+	t.Run("ed25519", func(t *testing.T) {
+		_, err := NewEd25519(ed25519PrivateKey, ed25519PublicAlternative)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewEd25519(ed25519PrivateKey[:10], ed25519PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, other, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		badSeed := append(ed25519.PrivateKey{}, other.Seed()...)
+		badSeed = append(badSeed, ed25519PublicKey...)
+		_, err = NewEd25519(badSeed, ed25519PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewEd25519Verifier(ed25519.PublicKey(ed25519PublicKey[:10]))
+		require.Error(t, err)
+	})
+}
@@ -14,8 +14,26 @@ type RsaSsaPkcs struct {
 	hash       crypto.Hash
 }
 
-func NewRsaSsaPkcs1(a Algorithm, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (RsaSsaPkcs, error) {
-	if publicKey == nil || privateKey == nil {
+func NewRsaSsaPkcs1(a Algorithm, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, options ...KeyOption) (RsaSsaPkcs, error) {
+	if privateKey == nil {
+		return RsaSsaPkcs{}, ErrNilKey
+	}
+
+	r, err := NewRsaSsaPkcs1Verifier(a, publicKey, options...)
+	if err != nil {
+		return RsaSsaPkcs{}, err
+	}
+	if err = checkRsaKeyPair(privateKey, publicKey); err != nil {
+		return RsaSsaPkcs{}, err
+	}
+
+	r.privateKey = privateKey
+	return r, nil
+}
+
+// NewRsaSsaPkcs1Verifier returns RsaSsaPkcs without private key, which can only verify signatures
+func NewRsaSsaPkcs1Verifier(a Algorithm, publicKey *rsa.PublicKey, options ...KeyOption) (RsaSsaPkcs, error) {
+	if publicKey == nil {
 		return RsaSsaPkcs{}, ErrNilKey
 	}
 
@@ -30,12 +48,14 @@ func NewRsaSsaPkcs1(a Algorithm, privateKey *rsa.PrivateKey, publicKey *rsa.Publ
 	default:
 		return RsaSsaPkcs{}, fmt.Errorf("algorithm %s is not RSASSA-PKCS1", a)
 	}
+	if err := checkRsaKeySize(publicKey, options); err != nil {
+		return RsaSsaPkcs{}, err
+	}
 
 	return RsaSsaPkcs{
-		privateKey: privateKey,
-		publicKey:  publicKey,
-		hash:       hash,
-		pool:       NewHashPool(hash.New),
+		publicKey: publicKey,
+		hash:      hash,
+		pool:      NewHashPool(hash.New),
 	}, nil
 }
 
@@ -58,10 +78,14 @@ func (r RsaSsaPkcs) Verify(payload, signature []byte) (bool, error) {
 }
 
 func (r RsaSsaPkcs) Size() int {
-	return r.privateKey.Size()
+	return r.publicKey.Size()
 }
 
 func (r RsaSsaPkcs) Sign(payload []byte) ([]byte, error) {
+	if r.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
 	digest, err := r.pool.Digest(payload)
 	if err != nil {
 		return nil, err
@@ -74,3 +98,29 @@ func (r RsaSsaPkcs) Sign(payload []byte) ([]byte, error) {
 
 	return signature, nil
 }
+
+// JWK returns JWK with the public key
+func (r RsaSsaPkcs) JWK() (JWK, error) {
+	return NewJWK(r.publicKey)
+}
+
+func checkRsaKeySize(publicKey *rsa.PublicKey, options []KeyOption) error {
+	if bits := publicKey.N.BitLen(); bits < MinRsaKeySize && !newKeyOptions(options).allowWeak {
+		return fmt.Errorf("%w: RSA key has %d bits, at least %d required", ErrWeakKey, bits, MinRsaKeySize)
+	}
+
+	return nil
+}
+
+// checkRsaKeyPair validates privateKey itself, so primes and exponent not matching
+// the embedded public key are detected
+func checkRsaKeyPair(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) error {
+	if !publicKey.Equal(&privateKey.PublicKey) {
+		return ErrKeyMismatch
+	}
+	if err := privateKey.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrKeyMismatch, err)
+	}
+
+	return nil
+}
@@ -32,31 +32,51 @@ type RsaSsaPss struct {
 	hash       crypto.Hash
 }
 
-func NewRsaSsaPss(a Algorithm, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (RsaSsaPss, error) {
-	if publicKey == nil || privateKey == nil {
+func NewRsaSsaPss(a Algorithm, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, options ...KeyOption) (RsaSsaPss, error) {
+	if privateKey == nil {
+		return RsaSsaPss{}, ErrNilKey
+	}
+
+	r, err := NewRsaSsaPssVerifier(a, publicKey, options...)
+	if err != nil {
+		return RsaSsaPss{}, err
+	}
+	if err = checkRsaKeyPair(privateKey, publicKey); err != nil {
+		return RsaSsaPss{}, err
+	}
+
+	r.privateKey = privateKey
+	return r, nil
+}
+
+// NewRsaSsaPssVerifier returns RsaSsaPss without private key, which can only verify signatures
+func NewRsaSsaPssVerifier(a Algorithm, publicKey *rsa.PublicKey, options ...KeyOption) (RsaSsaPss, error) {
+	if publicKey == nil {
 		return RsaSsaPss{}, ErrNilKey
 	}
 
 	var hash crypto.Hash
-	var options *rsa.PSSOptions
+	var pssOptions *rsa.PSSOptions
 
 	switch a {
 	case PS256:
-		hash, options = crypto.SHA256, pssOptions256
+		hash, pssOptions = crypto.SHA256, pssOptions256
 	case PS384:
-		hash, options = crypto.SHA384, pssOptions384
+		hash, pssOptions = crypto.SHA384, pssOptions384
 	case PS512:
-		hash, options = crypto.SHA512, pssOptions512
+		hash, pssOptions = crypto.SHA512, pssOptions512
 	default:
 		return RsaSsaPss{}, fmt.Errorf("algorithm %s is not RSASSA-PSS", a)
 	}
+	if err := checkRsaKeySize(publicKey, options); err != nil {
+		return RsaSsaPss{}, err
+	}
 
 	return RsaSsaPss{
-		publicKey:  publicKey,
-		privateKey: privateKey,
-		options:    options,
-		hash:       hash,
-		pool:       NewHashPool(hash.New),
+		publicKey: publicKey,
+		options:   pssOptions,
+		hash:      hash,
+		pool:      NewHashPool(hash.New),
 	}, nil
 }
 
@@ -79,10 +99,14 @@ func (r RsaSsaPss) Verify(payload, signature []byte) (bool, error) {
 }
 
 func (r RsaSsaPss) Size() int {
-	return r.privateKey.Size()
+	return r.publicKey.Size()
 }
 
 func (r RsaSsaPss) Sign(payload []byte) ([]byte, error) {
+	if r.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
 	digest, err := r.pool.Digest(payload)
 	if err != nil {
 		return nil, err
@@ -95,3 +119,8 @@ func (r RsaSsaPss) Sign(payload []byte) ([]byte, error) {
 
 	return signature, nil
 }
+
+// JWK returns JWK with the public key
+func (r RsaSsaPss) JWK() (JWK, error) {
+	return NewJWK(r.publicKey)
+}
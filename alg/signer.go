@@ -0,0 +1,55 @@
+package alg
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+)
+
+// NewSigner returns Signer for algorithm a using the private key.
+// Key type must match the algorithm family: []byte for HS*, *rsa.PrivateKey for RS* and PS*,
+// *ecdsa.PrivateKey for ES* and ed25519.PrivateKey for EdDSA
+func NewSigner(a Algorithm, privateKey crypto.PrivateKey, options ...KeyOption) (Signer, error) {
+	if privateKey == nil {
+		return nil, ErrNilKey
+	}
+
+	switch a {
+	case HS256, HS384, HS512:
+		key, ok := privateKey.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires secret, got %T", a, privateKey)
+		}
+
+		return NewHmacSha(a, string(key), options...)
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		key, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires RSA key, got %T", a, privateKey)
+		}
+		if a == PS256 || a == PS384 || a == PS512 {
+			return NewRsaSsaPss(a, key, &key.PublicKey, options...)
+		}
+
+		return NewRsaSsaPkcs1(a, key, &key.PublicKey, options...)
+	case ES256, ES384, ES512:
+		key, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires ECDSA key, got %T", a, privateKey)
+		}
+
+		return NewECDSA(a, key, &key.PublicKey)
+	case EdDSA:
+		key, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires Ed25519 key, got %T", a, privateKey)
+		}
+
+		public, _ := key.Public().(ed25519.PublicKey)
+		return NewEd25519(key, public)
+	default:
+		return nil, fmt.Errorf("algorithm %s does not support private keys", a)
+	}
+}
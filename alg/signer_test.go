@@ -0,0 +1,58 @@
+package alg
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSigner(t *testing.T) {
+	keys := map[Algorithm]interface{}{
+		HS256: []byte("0123456789abcdef0123456789abcdef"),
+		RS384: rsa384PrivateKey,
+		PS512: rsa512PrivateKey,
+		ES256: ecdsa256PrivateKey,
+		EdDSA: ed25519.PrivateKey(ed25519PrivateKey),
+	}
+	for a, key := range keys {
+		signer, err := NewSigner(a, key)
+		require.NoError(t, err, a)
+
+		payload := []byte("sign me")
+		signature, err := signer.Sign(payload)
+		require.NoError(t, err)
+
+		verifier, ok := signer.(Verifier)
+		require.True(t, ok)
+
+		ok, err = verifier.Verify(payload, signature)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	_, err := NewSigner(HS256, []byte("short"))
+	require.ErrorIs(t, err, ErrWeakKey)
+
+	_, err = NewSigner(HS256, []byte("short"), AllowWeakKeys())
+	require.NoError(t, err)
+
+	_, err = NewSigner(ES256, rsa384PrivateKey)
+	require.Error(t, err)
+
+	_, err = NewSigner(RS256, ecdsa256PrivateKey)
+	require.Error(t, err)
+
+	_, err = NewSigner(EdDSA, rsa384PrivateKey)
+	require.Error(t, err)
+
+	_, err = NewSigner(HS256, rsa384PrivateKey)
+	require.Error(t, err)
+
+	_, err = NewSigner(None, rsa384PrivateKey)
+	require.Error(t, err)
+
+	_, err = NewSigner(RS256, nil)
+	require.ErrorIs(t, err, ErrNilKey)
+}
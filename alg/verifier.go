@@ -0,0 +1,47 @@
+package alg
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+)
+
+// NewVerifier returns Verifier for algorithm a using only the public key.
+// Key type must match the algorithm family: *rsa.PublicKey for RS* and PS*,
+// *ecdsa.PublicKey for ES* and ed25519.PublicKey for EdDSA
+func NewVerifier(a Algorithm, publicKey crypto.PublicKey, options ...KeyOption) (Verifier, error) {
+	if publicKey == nil {
+		return nil, ErrNilKey
+	}
+
+	switch a {
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		key, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires RSA key, got %T", a, publicKey)
+		}
+		if a == PS256 || a == PS384 || a == PS512 {
+			return NewRsaSsaPssVerifier(a, key, options...)
+		}
+
+		return NewRsaSsaPkcs1Verifier(a, key, options...)
+	case ES256, ES384, ES512:
+		key, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires ECDSA key, got %T", a, publicKey)
+		}
+
+		return NewECDSAVerifier(a, key)
+	case EdDSA:
+		key, ok := publicKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s requires Ed25519 key, got %T", a, publicKey)
+		}
+
+		return NewEd25519Verifier(key)
+	default:
+		return nil, fmt.Errorf("algorithm %s does not support public keys", a)
+	}
+}
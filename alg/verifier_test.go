@@ -0,0 +1,80 @@
+package alg
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerifier(t *testing.T) {
+	payload := []byte("verify me with public key only")
+
+	t.Run("rsa", func(t *testing.T) {
+		signer, err := NewRsaSsaPkcs1(RS256, rsa384PrivateKey, rsa384PublicKey)
+		require.NoError(t, err)
+
+		signature, err := signer.Sign(payload)
+		require.NoError(t, err)
+		testVerifier(t, RS256, rsa384PublicKey, payload, signature)
+	})
+	t.Run("rsa pss", func(t *testing.T) {
+		signer, err := NewRsaSsaPss(PS256, rsa384PrivateKey, rsa384PublicKey)
+		require.NoError(t, err)
+
+		signature, err := signer.Sign(payload)
+		require.NoError(t, err)
+		testVerifier(t, PS256, rsa384PublicKey, payload, signature)
+	})
+	t.Run("ecdsa", func(t *testing.T) {
+		signer, err := NewECDSA(ES384, ecdsa384PrivateKey, ecdsa384PublicKey)
+		require.NoError(t, err)
+
+		signature, err := signer.Sign(payload)
+		require.NoError(t, err)
+		testVerifier(t, ES384, ecdsa384PublicKey, payload, signature)
+	})
+	t.Run("ed25519", func(t *testing.T) {
+		signer, err := NewEd25519(ed25519PrivateKey, ed25519PublicKey)
+		require.NoError(t, err)
+
+		signature, err := signer.Sign(payload)
+		require.NoError(t, err)
+		testVerifier(t, EdDSA, ed25519.PublicKey(ed25519PublicKey), payload, signature)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		_, err := NewVerifier(ES256, rsa384PublicKey)
+		require.Error(t, err)
+
+		_, err = NewVerifier(RS256, ecdsa256PublicKey)
+		require.Error(t, err)
+
+		_, err = NewVerifier(EdDSA, (*ecdsa.PublicKey)(nil))
+		require.Error(t, err)
+
+		_, err = NewVerifier(HS256, rsa384PublicKey)
+		require.Error(t, err)
+
+		_, err = NewVerifier(RS256, nil)
+		require.ErrorIs(t, err, ErrNilKey)
+	})
+}
+
+func testVerifier(t *testing.T, a Algorithm, publicKey interface{}, payload, signature []byte) {
+	t.Helper()
+
+	verifier, err := NewVerifier(a, publicKey)
+	require.NoError(t, err)
+
+	ok, err := verifier.Verify(payload, signature)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	signer, ok := verifier.(Signer)
+	require.True(t, ok)
+
+	_, err = signer.Sign(payload)
+	require.ErrorIs(t, err, ErrNoPrivateKey)
+}
@@ -0,0 +1,354 @@
+package jwt
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+// Builder creates tokens step by step, e.g.
+//
+//	jwt.Build(alg.ES256).Subject("user").ExpiresIn(15 * time.Minute).Claim("role", "admin").WriteString()
+//
+// "iat" is stamped from the builder clock when the token is built unless set explicitly.
+// Errors of intermediate steps are returned by Token, Write and WriteString
+type Builder struct {
+	token     Token
+	clock     func() time.Time
+	ttl       time.Duration
+	notBefore bool
+	generator IdGenerator
+	check     Check
+	err       error
+}
+
+// Build returns Builder of token signed by algorithm a with registered signer
+func Build(a alg.Algorithm) *Builder {
+	return &Builder{
+		token: NewToken(a),
+		clock: time.Now,
+	}
+}
+
+// Clock replaces time source used to stamp "iat", "nbf" and "exp", time.Now is used by default
+func (b *Builder) Clock(clock func() time.Time) *Builder {
+	b.clock = clock
+	return b
+}
+
+// Type sets "typ" header
+func (b *Builder) Type(t Type) *Builder {
+	b.token.Header.Type = t
+	return b
+}
+
+// KeyId sets "kid" header
+func (b *Builder) KeyId(keyId string) *Builder {
+	b.token.Header.KeyId = keyId
+	return b
+}
+
+// Issuer sets "iss" claim
+func (b *Builder) Issuer(issuer string) *Builder {
+	b.token.Claims.Issuer = issuer
+	return b
+}
+
+// Subject sets "sub" claim
+func (b *Builder) Subject(subject string) *Builder {
+	b.token.Claims.Subject = subject
+	return b
+}
+
+// Audience sets "aud" claim
+func (b *Builder) Audience(audience ...string) *Builder {
+	b.token.Claims.Audience = audience
+	return b
+}
+
+// Id sets "jti" claim
+func (b *Builder) Id(id string) *Builder {
+	b.token.Claims.Id = id
+	return b
+}
+
+// RandomId sets "jti" claim to NewRandomId result when the token is built, unless Id is set
+func (b *Builder) RandomId() *Builder {
+	return b.GenerateId(NewRandomId)
+}
+
+// GenerateId sets "jti" claim to generated identifier when the token is built, unless Id is set
+func (b *Builder) GenerateId(generator IdGenerator) *Builder {
+	b.generator = generator
+	return b
+}
+
+// IssuedAt sets "iat" claim instead of the current time
+func (b *Builder) IssuedAt(moment time.Time) *Builder {
+	b.token.Claims.IssuedAt = NewPosixTime(moment)
+	return b
+}
+
+// ExpiresAt sets "exp" claim
+func (b *Builder) ExpiresAt(moment time.Time) *Builder {
+	b.token.Claims.ExpiresAt = NewPosixTime(moment)
+	return b
+}
+
+// ExpiresIn sets "exp" claim to "iat" plus ttl when the token is built, unless ExpiresAt is set
+func (b *Builder) ExpiresIn(ttl time.Duration) *Builder {
+	b.ttl = ttl
+	return b
+}
+
+// NotBefore sets "nbf" claim
+func (b *Builder) NotBefore(moment time.Time) *Builder {
+	b.token.Claims.NotBefore = NewPosixTime(moment)
+	return b
+}
+
+// NotBeforeNow sets "nbf" claim equal to "iat" when the token is built, unless NotBefore is set
+func (b *Builder) NotBeforeNow() *Builder {
+	b.notBefore = true
+	return b
+}
+
+// Claim sets claim at dot-separated path, see Claims.Put
+func (b *Builder) Claim(path string, value interface{}) *Builder {
+	if b.err == nil {
+		b.err = b.token.Claims.Put(path, value)
+	}
+
+	return b
+}
+
+// ClientId sets "client_id" claim
+func (b *Builder) ClientId(clientId string) *Builder {
+	return b.Claim(ClientIdClaim, clientId)
+}
+
+// Scope sets space-delimited "scope" claim
+func (b *Builder) Scope(scopes ...string) *Builder {
+	return b.Claim(ScopeClaim, strings.Join(scopes, " "))
+}
+
+// AuthTime sets "auth_time" claim
+func (b *Builder) AuthTime(moment time.Time) *Builder {
+	return b.Claim(AuthTimeClaim, moment.Unix())
+}
+
+// Acr sets "acr" claim
+func (b *Builder) Acr(acr string) *Builder {
+	return b.Claim(AcrClaim, acr)
+}
+
+// Amr sets "amr" claim
+func (b *Builder) Amr(methods ...string) *Builder {
+	return b.Claim(AmrClaim, methods)
+}
+
+// Confirmation sets "cnf" claim binding the token to a key or client certificate
+func (b *Builder) Confirmation(cnf Confirmation) *Builder {
+	return b.Claim(ConfirmationClaim, cnf)
+}
+
+// Actor sets "act" claim, see Template.Exchange to preserve delegation chain
+func (b *Builder) Actor(actor Actor) *Builder {
+	return b.Claim(ActorClaim, actor)
+}
+
+// MayAct sets "may_act" claim
+func (b *Builder) MayAct(actor Actor) *Builder {
+	return b.Claim(MayActClaim, actor)
+}
+
+// Event adds Security Event Token event of eventType, see Claims.AddEvent
+func (b *Builder) Event(eventType string, payload interface{}) *Builder {
+	if b.err == nil {
+		b.err = b.token.Claims.AddEvent(eventType, payload)
+	}
+
+	return b
+}
+
+// Transaction sets "txn" claim
+func (b *Builder) Transaction(txn string) *Builder {
+	return b.Claim(TransactionClaim, txn)
+}
+
+// TimeOfEvent sets "toe" claim
+func (b *Builder) TimeOfEvent(moment time.Time) *Builder {
+	return b.Claim(TimeOfEventClaim, moment.Unix())
+}
+
+// Token returns built token, the builder can be reused to build more tokens
+func (b *Builder) Token() (Token, error) {
+	if b.err != nil {
+		return Token{}, b.err
+	}
+
+	t := b.token
+	claims := &t.Claims
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = NewPosixTime(b.clock())
+	}
+	if b.ttl > 0 && claims.ExpiresAt == nil {
+		claims.ExpiresAt = NewPosixTime(claims.IssuedAt.Add(b.ttl))
+	}
+	if b.notBefore && claims.NotBefore == nil {
+		claims.NotBefore = NewPosixTime(claims.IssuedAt.Time)
+	}
+	if b.generator != nil && len(claims.Id) == 0 {
+		id, err := b.generator()
+		if err != nil {
+			return Token{}, err
+		}
+
+		claims.Id = id
+	}
+	if b.check != nil {
+		if err := b.check(t); err != nil {
+			return Token{}, err
+		}
+	}
+
+	return t, nil
+}
+
+// Write builds the token and signs it, see Token.Write
+func (b *Builder) Write(options ...WriteOption) (*bytes.Buffer, error) {
+	t, err := b.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Write(options...)
+}
+
+// WriteString builds the token and returns its compact serialization, see Token.WriteString
+func (b *Builder) WriteString(options ...WriteOption) (string, error) {
+	t, err := b.Token()
+	if err != nil {
+		return "", err
+	}
+
+	return t.WriteString(options...)
+}
+
+// TemplateOption configures Template
+type TemplateOption func(t *Template)
+
+// IssuedBy stamps "iss" claim
+func IssuedBy(issuer string) TemplateOption {
+	return func(t *Template) {
+		t.issuer = issuer
+	}
+}
+
+// IssuedFor stamps "aud" claim
+func IssuedFor(audience ...string) TemplateOption {
+	return func(t *Template) {
+		t.audience = audience
+	}
+}
+
+// ValidFor stamps "exp" claim ttl after "iat"
+func ValidFor(ttl time.Duration) TemplateOption {
+	return func(t *Template) {
+		t.ttl = ttl
+	}
+}
+
+// WithTemplateType stamps "typ" header, JWT is used by default
+func WithTemplateType(typ Type) TemplateOption {
+	return func(t *Template) {
+		t.typ = typ
+	}
+}
+
+// WithTemplateKeyId stamps "kid" header
+func WithTemplateKeyId(keyId string) TemplateOption {
+	return func(t *Template) {
+		t.keyId = keyId
+	}
+}
+
+// WithTemplateClaim stamps default claim at dot-separated path, builders may override it
+func WithTemplateClaim(path string, value interface{}) TemplateOption {
+	return func(t *Template) {
+		t.claims = append(t.claims, templateClaim{path: path, value: value})
+	}
+}
+
+// WithIdGenerator replaces "jti" generator, NewRandomId is used by default
+func WithIdGenerator(generator IdGenerator) TemplateOption {
+	return func(t *Template) {
+		t.generator = generator
+	}
+}
+
+// WithTemplateClock replaces time source of built tokens, time.Now is used by default
+func WithTemplateClock(clock func() time.Time) TemplateOption {
+	return func(t *Template) {
+		t.clock = clock
+	}
+}
+
+// Template is reusable configuration of tokens of one issuer.
+// Tokens built from it have "iat" and "nbf" set to the current time and generated "jti"
+type Template struct {
+	algorithm alg.Algorithm
+	typ       Type
+	keyId     string
+	issuer    string
+	audience  []string
+	ttl       time.Duration
+	claims    []templateClaim
+	generator IdGenerator
+	clock     func() time.Time
+	// check rejects built tokens not conforming to the template profile
+	check Check
+}
+
+type templateClaim struct {
+	path  string
+	value interface{}
+}
+
+// NewTemplate returns Template of tokens signed by algorithm a with registered signer
+func NewTemplate(a alg.Algorithm, options ...TemplateOption) Template {
+	t := Template{
+		algorithm: a,
+		typ:       JsonWebTokenType,
+		generator: NewRandomId,
+		clock:     time.Now,
+	}
+	for _, option := range options {
+		option(&t)
+	}
+
+	return t
+}
+
+// Build returns Builder with stamped template claims
+func (t Template) Build() *Builder {
+	b := Build(t.algorithm).
+		Clock(t.clock).
+		Type(t.typ).
+		KeyId(t.keyId).
+		Issuer(t.issuer).
+		ExpiresIn(t.ttl).
+		NotBeforeNow().
+		GenerateId(t.generator)
+	if len(t.audience) > 0 {
+		b.Audience(t.audience...)
+	}
+	for _, claim := range t.claims {
+		b.Claim(claim.path, claim.value)
+	}
+	b.check = t.check
+
+	return b
+}
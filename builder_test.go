@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+
+	t.Run("claims", func(t *testing.T) {
+		s, err := Build(alg.HS256).
+			Clock(clock).
+			KeyId("key").
+			Issuer("issuer").
+			Subject("user").
+			Audience("api", "web").
+			Id("id").
+			ExpiresIn(15*time.Minute).
+			Claim("role", "admin").
+			Claim("tenant.id", 42).
+			WriteString()
+		require.NoError(t, err)
+
+		token, err := Parse([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, "key", token.Header.KeyId)
+		assert.Equal(t, JsonWebTokenType, token.Header.Type)
+		assert.Equal(t, "issuer", token.Claims.Issuer)
+		assert.Equal(t, "user", token.Claims.Subject)
+		assert.Equal(t, Audience{"api", "web"}, token.Claims.Audience)
+		assert.Equal(t, "id", token.Claims.Id)
+		assert.Equal(t, now.Unix(), token.Claims.IssuedAt.Unix())
+		assert.Equal(t, now.Add(15*time.Minute).Unix(), token.Claims.ExpiresAt.Unix())
+		assert.Nil(t, token.Claims.NotBefore)
+
+		role, err := token.Claims.GetString("role")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", role)
+
+		tenant, err := token.Claims.GetInt64("tenant.id")
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), tenant)
+	})
+	t.Run("explicit times", func(t *testing.T) {
+		issued := now.Add(-time.Hour)
+		token, err := Build(alg.HS256).
+			Clock(clock).
+			IssuedAt(issued).
+			ExpiresIn(time.Minute).
+			ExpiresAt(now.Add(time.Hour)).
+			NotBefore(now).
+			NotBeforeNow().
+			Token()
+		require.NoError(t, err)
+		assert.Equal(t, issued.Unix(), token.Claims.IssuedAt.Unix())
+		assert.Equal(t, now.Add(time.Hour).Unix(), token.Claims.ExpiresAt.Unix())
+		assert.Equal(t, now.Unix(), token.Claims.NotBefore.Unix())
+	})
+	t.Run("random id", func(t *testing.T) {
+		b := Build(alg.HS256).RandomId()
+		first, err := b.Token()
+		require.NoError(t, err)
+		second, err := b.Token()
+		require.NoError(t, err)
+
+		assert.Len(t, first.Claims.Id, 22)
+		assert.NotEqual(t, first.Claims.Id, second.Claims.Id)
+
+		token, err := Build(alg.HS256).RandomId().Id("id").Token()
+		require.NoError(t, err)
+		assert.Equal(t, "id", token.Claims.Id)
+
+		token, err = Build(alg.HS256).GenerateId(NewUUID).Token()
+		require.NoError(t, err)
+		assert.True(t, IsUUID(token.Claims.Id))
+	})
+	t.Run("claim error", func(t *testing.T) {
+		b := Build(alg.HS256).Claim("bad", make(chan int)).Subject("user")
+		_, err := b.Token()
+		assert.Error(t, err)
+		_, err = b.WriteString()
+		assert.Error(t, err)
+		_, err = b.Write()
+		assert.Error(t, err)
+
+		_, err = Build(alg.HS256).Claim("sub", 1).Token()
+		assert.Error(t, err)
+	})
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, err := Build(alg.Algorithm("unknown")).WriteString()
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplate(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	template := NewTemplate(alg.HS256,
+		IssuedBy("https://auth.example.com"),
+		IssuedFor("api"),
+		ValidFor(time.Hour),
+		WithTemplateType(AccessTokenType),
+		WithTemplateKeyId("key"),
+		WithTemplateClaim("scope", "read"),
+		WithIdGenerator(NewUUID),
+		WithTemplateClock(func() time.Time {
+			return now
+		}),
+	)
+
+	first, err := template.Build().Subject("user").Token()
+	require.NoError(t, err)
+	assert.Equal(t, AccessTokenType, first.Header.Type)
+	assert.Equal(t, "key", first.Header.KeyId)
+	assert.Equal(t, "https://auth.example.com", first.Claims.Issuer)
+	assert.Equal(t, Audience{"api"}, first.Claims.Audience)
+	assert.Equal(t, "user", first.Claims.Subject)
+	assert.Equal(t, now.Unix(), first.Claims.IssuedAt.Unix())
+	assert.Equal(t, now.Unix(), first.Claims.NotBefore.Unix())
+	assert.Equal(t, now.Add(time.Hour).Unix(), first.Claims.ExpiresAt.Unix())
+	assert.True(t, IsUUID(first.Claims.Id))
+	assert.True(t, first.Claims.HasScope("read"))
+
+	second, err := template.Build().Claim("scope", "write").Token()
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Claims.Id, second.Claims.Id)
+	assert.Empty(t, second.Claims.Subject)
+	assert.True(t, second.Claims.HasScope("write"))
+
+	s, err := template.Build().WriteString()
+	require.NoError(t, err)
+
+	_, err = Parse([]byte(s))
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+
+	token, err := Parse([]byte(s), WithTypes(AccessTokenType))
+	require.NoError(t, err)
+	assert.NoError(t, NewValidator(
+		WithClock(func() time.Time { return now }),
+		WithIssuer("https://auth.example.com"),
+		WithAudience("api"),
+	).Validate(token))
+}
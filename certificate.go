@@ -0,0 +1,184 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/sha1" // #nosec G505 -- x5t is defined as SHA-1 thumbprint by RFC 7515
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+var (
+	ErrNoCertificateChain = errors.New("x5c is missing")
+	ErrBadCertificate     = errors.New("bad certificate")
+	ErrThumbprintMismatch = errors.New("certificate thumbprint mismatch")
+	ErrNoTrustedRoots     = errors.New("no trusted root certificates")
+)
+
+// CertificateOption configures CertificateVerifier
+type CertificateOption func(v *CertificateVerifier)
+
+// WithIntermediates adds intermediate certificates which may be used to build the chain
+// in addition to ones included in "x5c"
+func WithIntermediates(intermediates ...*x509.Certificate) CertificateOption {
+	return func(v *CertificateVerifier) {
+		v.intermediates = intermediates
+	}
+}
+
+// WithExtKeyUsages restricts extended key usages of the leaf certificate, any usage is accepted by default
+func WithExtKeyUsages(usages ...x509.ExtKeyUsage) CertificateOption {
+	return func(v *CertificateVerifier) {
+		v.usages = usages
+	}
+}
+
+// WithSystemRoots trusts system root certificates when roots of NewCertificateVerifier are nil
+func WithSystemRoots() CertificateOption {
+	return func(v *CertificateVerifier) {
+		v.systemRoots = true
+	}
+}
+
+// WithCertificateClock replaces time source used to check certificates expiration
+func WithCertificateClock(clock func() time.Time) CertificateOption {
+	return func(v *CertificateVerifier) {
+		v.clock = clock
+	}
+}
+
+// CertificateVerifier verifies tokens signed by the key of the leaf certificate from "x5c" header.
+// The chain must be valid against trusted roots, "x5t" and "x5t#S256" (if present) must match the leaf
+type CertificateVerifier struct {
+	roots         *x509.CertPool
+	systemRoots   bool
+	intermediates []*x509.Certificate
+	usages        []x509.ExtKeyUsage
+	clock         func() time.Time
+}
+
+// NewCertificateVerifier returns CertificateVerifier trusting certificates issued by roots.
+// Nil roots trust nothing and every token is rejected with ErrNoTrustedRoots, unless WithSystemRoots is given
+func NewCertificateVerifier(roots *x509.CertPool, options ...CertificateOption) CertificateVerifier {
+	v := CertificateVerifier{
+		roots:  roots,
+		usages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		clock:  time.Now,
+	}
+	for _, option := range options {
+		option(&v)
+	}
+
+	return v
+}
+
+// ResolveVerifier validates certificate chain from the header and returns verifier
+// with the leaf certificate public key
+func (v CertificateVerifier) ResolveVerifier(header Header) (alg.Verifier, error) {
+	if v.roots == nil && !v.systemRoots {
+		return nil, ErrNoTrustedRoots
+	}
+
+	chain, err := ParseCertificateChain(header.X509CertChain)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := chain[0]
+	if err = checkThumbprints(header, leaf); err != nil {
+		return nil, err
+	}
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, fmt.Errorf("%w: key usage does not allow digital signature", ErrBadCertificate)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range v.intermediates {
+		intermediates.AddCert(cert)
+	}
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.clock(),
+		KeyUsages:     v.usages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadCertificate, err)
+	}
+
+	return alg.NewVerifier(header.Algorithm, leaf.PublicKey)
+}
+
+// SetCertificateChain puts chain (leaf first) into "x5c" header and sets "x5t#S256" of the leaf.
+// "x5t" is recomputed for the new leaf if it is set, empty chain clears all of them
+func (t *Token) SetCertificateChain(chain ...*x509.Certificate) {
+	if len(chain) == 0 {
+		t.Header.X509CertChain = nil
+		t.Header.X509Thumbprint = ""
+		t.Header.X509ThumbprintS256 = ""
+		return
+	}
+
+	t.Header.X509CertChain = make([]string, len(chain))
+	for i, cert := range chain {
+		t.Header.X509CertChain[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+	if len(t.Header.X509Thumbprint) > 0 {
+		t.Header.X509Thumbprint = certificateThumbprint(chain[0], crypto.SHA1)
+	}
+	t.Header.X509ThumbprintS256 = certificateThumbprint(chain[0], crypto.SHA256)
+}
+
+// ParseCertificateChain decodes "x5c" value, the leaf certificate goes first
+func ParseCertificateChain(x5c []string) ([]*x509.Certificate, error) {
+	if len(x5c) == 0 {
+		return nil, ErrNoCertificateChain
+	}
+
+	chain := make([]*x509.Certificate, len(x5c))
+	for i, encoded := range x5c {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: x5c[%d]: %v", ErrBadCertificate, i, err)
+		}
+
+		chain[i], err = x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("%w: x5c[%d]: %v", ErrBadCertificate, i, err)
+		}
+	}
+
+	return chain, nil
+}
+
+func checkThumbprints(header Header, leaf *x509.Certificate) error {
+	if len(header.X509Thumbprint) > 0 &&
+		!isConstTimeEqualsString(header.X509Thumbprint, certificateThumbprint(leaf, crypto.SHA1)) {
+		return fmt.Errorf("%w: x5t", ErrThumbprintMismatch)
+	}
+	if len(header.X509ThumbprintS256) > 0 &&
+		!isConstTimeEqualsString(header.X509ThumbprintS256, certificateThumbprint(leaf, crypto.SHA256)) {
+		return fmt.Errorf("%w: x5t#S256", ErrThumbprintMismatch)
+	}
+
+	return nil
+}
+
+func certificateThumbprint(cert *x509.Certificate, hash crypto.Hash) string {
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(cert.Raw) // #nosec G401
+		return toBase64(sum[:])
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	return toBase64(sum[:])
+}
@@ -0,0 +1,236 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, name string, parent *testCertificate, usage x509.KeyUsage, notAfter time.Time) testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              usage,
+		BasicConstraintsValid: true,
+		IsCA:                  usage&x509.KeyUsageCertSign != 0,
+	}
+	if !template.IsCA {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testCertificate{cert: cert, key: key}
+}
+
+func signWithCertificate(t *testing.T, leaf testCertificate, modify func(h *Header)) []byte {
+	t.Helper()
+
+	es256, err := alg.NewECDSA(alg.ES256, leaf.key, &leaf.key.PublicKey)
+	require.NoError(t, err)
+	Register(alg.ES256, es256, es256)
+
+	token := NewToken(alg.ES256)
+	token.Header.X509CertChain = []string{base64.StdEncoding.EncodeToString(leaf.cert.Raw)}
+	token.Claims.Subject = "partner"
+	if modify != nil {
+		modify(&token.Header)
+	}
+
+	buf, err := token.Write()
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+func TestCertificateVerifier(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	root := newTestCertificate(t, "root", nil, x509.KeyUsageCertSign, expires)
+	leaf := newTestCertificate(t, "leaf", &root, x509.KeyUsageDigitalSignature, expires)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+	verifier := NewCertificateVerifier(roots)
+
+	t.Run("valid", func(t *testing.T) {
+		data := signWithCertificate(t, leaf, func(h *Header) {
+			h.X509Thumbprint = certificateThumbprint(leaf.cert, crypto.SHA1)
+			h.X509ThumbprintS256 = certificateThumbprint(leaf.cert, crypto.SHA256)
+		})
+
+		token, err := Parse(data, WithVerifierResolver(verifier))
+		require.NoError(t, err)
+		assert.Equal(t, "partner", token.Claims.Subject)
+	})
+	t.Run("untrusted root", func(t *testing.T) {
+		other := newTestCertificate(t, "other", nil, x509.KeyUsageCertSign, expires)
+		pool := x509.NewCertPool()
+		pool.AddCert(other.cert)
+
+		_, err := Parse(signWithCertificate(t, leaf, nil), WithVerifierResolver(NewCertificateVerifier(pool)))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("nil roots", func(t *testing.T) {
+		_, err := Parse(signWithCertificate(t, leaf, nil), WithVerifierResolver(NewCertificateVerifier(nil)))
+		require.True(t, errors.Is(err, ErrNoTrustedRoots))
+
+		_, err = Parse(signWithCertificate(t, leaf, nil), WithVerifierResolver(NewCertificateVerifier(nil, WithSystemRoots())))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("intermediate", func(t *testing.T) {
+		intermediate := newTestCertificate(t, "intermediate", &root, x509.KeyUsageCertSign, expires)
+		child := newTestCertificate(t, "child", &intermediate, x509.KeyUsageDigitalSignature, expires)
+
+		data := signWithCertificate(t, child, func(h *Header) {
+			h.X509CertChain = append(h.X509CertChain, base64.StdEncoding.EncodeToString(intermediate.cert.Raw))
+		})
+		_, err := Parse(data, WithVerifierResolver(verifier))
+		require.NoError(t, err)
+
+		_, err = Parse(signWithCertificate(t, child, nil), WithVerifierResolver(verifier))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+
+		_, err = Parse(signWithCertificate(t, child, nil),
+			WithVerifierResolver(NewCertificateVerifier(roots, WithIntermediates(intermediate.cert))))
+		require.NoError(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		clock := func() time.Time {
+			return expires.Add(time.Minute)
+		}
+
+		_, err := Parse(signWithCertificate(t, leaf, nil),
+			WithVerifierResolver(NewCertificateVerifier(roots, WithCertificateClock(clock))))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("key usage", func(t *testing.T) {
+		encipher := newTestCertificate(t, "encipher", &root, x509.KeyUsageKeyEncipherment, expires)
+
+		_, err := Parse(signWithCertificate(t, encipher, nil), WithVerifierResolver(verifier))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("ext key usage", func(t *testing.T) {
+		_, err := Parse(signWithCertificate(t, leaf, nil),
+			WithVerifierResolver(NewCertificateVerifier(roots, WithExtKeyUsages(x509.ExtKeyUsageCodeSigning))))
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("thumbprint mismatch", func(t *testing.T) {
+		data := signWithCertificate(t, leaf, func(h *Header) {
+			h.X509ThumbprintS256 = certificateThumbprint(root.cert, crypto.SHA256)
+		})
+		_, err := Parse(data, WithVerifierResolver(verifier))
+		require.True(t, errors.Is(err, ErrThumbprintMismatch))
+
+		data = signWithCertificate(t, leaf, func(h *Header) {
+			h.X509Thumbprint = "bad"
+		})
+		_, err = Parse(data, WithVerifierResolver(verifier))
+		require.True(t, errors.Is(err, ErrThumbprintMismatch))
+	})
+	t.Run("missing chain", func(t *testing.T) {
+		data := signWithCertificate(t, leaf, func(h *Header) {
+			h.X509CertChain = nil
+		})
+		_, err := Parse(data, WithVerifierResolver(verifier))
+		require.True(t, errors.Is(err, ErrNoCertificateChain))
+
+		_, err = ParseCertificateChain([]string{"not base64"})
+		require.True(t, errors.Is(err, ErrBadCertificate))
+
+		_, err = ParseCertificateChain([]string{"bm90IGRlcg=="})
+		require.True(t, errors.Is(err, ErrBadCertificate))
+	})
+	t.Run("wrong signer", func(t *testing.T) {
+		other := newTestCertificate(t, "other", &root, x509.KeyUsageDigitalSignature, expires)
+		data := signWithCertificate(t, other, func(h *Header) {
+			h.X509CertChain = []string{base64.StdEncoding.EncodeToString(leaf.cert.Raw)}
+		})
+
+		_, err := Parse(data, WithVerifierResolver(verifier))
+		require.Equal(t, ErrIncorrectSignature, err)
+	})
+}
+
+func TestToken_SetCertificateChain(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	root := newTestCertificate(t, "root", nil, x509.KeyUsageCertSign, expires)
+	intermediate := newTestCertificate(t, "intermediate", &root, x509.KeyUsageCertSign, expires)
+	leaf := newTestCertificate(t, "leaf", &intermediate, x509.KeyUsageDigitalSignature, expires)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	t.Run("explicit", func(t *testing.T) {
+		token := NewToken(alg.ES256)
+		token.SetCertificateChain(leaf.cert, intermediate.cert)
+		require.Len(t, token.Header.X509CertChain, 2)
+		assert.Equal(t, certificateThumbprint(leaf.cert, crypto.SHA256), token.Header.X509ThumbprintS256)
+
+		token.SetCertificateChain()
+		assert.Empty(t, token.Header.X509CertChain)
+		assert.Empty(t, token.Header.X509ThumbprintS256)
+	})
+	t.Run("replaced chain", func(t *testing.T) {
+		token := NewToken(alg.ES256)
+		token.SetCertificateChain(intermediate.cert)
+		token.Header.X509Thumbprint = certificateThumbprint(intermediate.cert, crypto.SHA1)
+
+		token.SetCertificateChain(leaf.cert, intermediate.cert)
+		assert.Equal(t, certificateThumbprint(leaf.cert, crypto.SHA1), token.Header.X509Thumbprint)
+		assert.Equal(t, certificateThumbprint(leaf.cert, crypto.SHA256), token.Header.X509ThumbprintS256)
+
+		token.SetCertificateChain()
+		assert.Empty(t, token.Header.X509Thumbprint)
+		assert.Empty(t, token.Header.X509ThumbprintS256)
+	})
+	t.Run("certified signer", func(t *testing.T) {
+		es256, err := alg.NewECDSA(alg.ES256, leaf.key, &leaf.key.PublicKey)
+		require.NoError(t, err)
+
+		signer, err := alg.NewCertifiedSigner(alg.ES256, es256, leaf.cert, intermediate.cert)
+		require.NoError(t, err)
+		Register(alg.ES256, es256, signer)
+
+		s, err := NewToken(alg.ES256).WriteString()
+		require.NoError(t, err)
+
+		token, err := Parse([]byte(s), WithVerifierResolver(NewCertificateVerifier(roots)))
+		require.NoError(t, err)
+		assert.Len(t, token.Header.X509CertChain, 2)
+		assert.Equal(t, certificateThumbprint(leaf.cert, crypto.SHA256), token.Header.X509ThumbprintS256)
+	})
+}
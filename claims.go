@@ -0,0 +1,419 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	claimPathSeparator = "."
+)
+
+var (
+	ErrInvalidClaim = errors.New("claim has unexpected type")
+)
+
+// Has reports whether the claim at dot-separated path exists, e.g. "address.country"
+func (c Claims) Has(path string) bool {
+	_, err := c.lookup(path)
+	return err == nil
+}
+
+// Keys returns sorted names of top-level claims, registered ones included
+func (c Claims) Keys() ([]string, error) {
+	fields, err := c.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.key)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// GetString returns string claim at dot-separated path.
+// Getters return ErrMissingClaim if the path does not exist and ErrInvalidClaim if the value has another type
+func (c Claims) GetString(path string) (string, error) {
+	var value string
+	err := c.getValue(path, &value, "string")
+	return value, err
+}
+
+// GetInt64 returns integer claim at dot-separated path
+func (c Claims) GetInt64(path string) (int64, error) {
+	number, err := c.getNumber(path)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := number.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", ErrInvalidClaim, path)
+	}
+
+	return value, nil
+}
+
+// GetBool returns boolean claim at dot-separated path
+func (c Claims) GetBool(path string) (bool, error) {
+	var value bool
+	err := c.getValue(path, &value, "boolean")
+	return value, err
+}
+
+// GetStrings returns array of strings at dot-separated path, single string is returned as one element array
+func (c Claims) GetStrings(path string) ([]string, error) {
+	raw, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s string
+	if err = json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, nil
+	}
+
+	var values []string
+	if err = json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("%w: %s must be a string or array of strings", ErrInvalidClaim, path)
+	}
+
+	return values, nil
+}
+
+// GetTime returns NumericDate claim (seconds since epoch, possibly fractional) at dot-separated path
+func (c Claims) GetTime(path string) (time.Time, error) {
+	number, err := c.getNumber(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if seconds, err := number.Int64(); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	seconds, err := number.Float64()
+	if err != nil || math.IsInf(seconds, 0) {
+		return time.Time{}, fmt.Errorf("%w: %s must be a numeric date", ErrInvalidClaim, path)
+	}
+
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+}
+
+// Put sets claim at dot-separated path to JSON encoded value, missing parent objects are created.
+// Registered claims are updated as well, other claims are preserved
+func (c *Claims) Put(path string, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	fields, err := c.fields()
+	if err != nil {
+		return err
+	}
+	if err = fields.put(strings.Split(path, claimPathSeparator), encoded); err != nil {
+		return err
+	}
+
+	return c.replace(fields)
+}
+
+// Delete removes claim at dot-separated path, it does nothing if the path does not exist
+func (c *Claims) Delete(path string) error {
+	fields, err := c.fields()
+	if err != nil {
+		return err
+	}
+
+	deleted, err := fields.delete(strings.Split(path, claimPathSeparator))
+	if err != nil || !deleted {
+		return err
+	}
+
+	return c.replace(fields)
+}
+
+// fields returns top-level claims from raw in their order with registered claims from BasicClaims applied.
+// Raw values of registered claims are kept while BasicClaims still holds the same values
+func (c Claims) fields() (claimObject, error) {
+	var fields, original claimObject
+	if len(c.raw) > 0 && !bytes.Equal(c.raw, []byte("null")) {
+		var err error
+		if fields, err = decodeClaimObject(c.raw); err != nil {
+			return nil, err
+		}
+
+		var basic BasicClaims
+		if err = json.Unmarshal(c.raw, &basic); err != nil {
+			return nil, err
+		}
+		if original, err = encodeClaimObject(basic); err != nil {
+			return nil, err
+		}
+	}
+
+	registered, err := encodeClaimObject(c.BasicClaims)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].key < registered[j].key
+	})
+
+	for _, field := range registered {
+		if value, ok := original.get(field.key); ok && bytes.Equal(value, field.value) {
+			if _, ok = fields.get(field.key); ok {
+				continue
+			}
+		}
+
+		fields.set(field.key, field.value)
+	}
+
+	return fields, nil
+}
+
+// lookup returns raw value at dot-separated path
+func (c Claims) lookup(path string) (json.RawMessage, error) {
+	fields, err := c.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(path, claimPathSeparator)
+	for i, segment := range segments {
+		raw, ok := fields.get(segment)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingClaim, path)
+		}
+		if i == len(segments)-1 {
+			return raw, nil
+		}
+
+		if fields, err = decodeClaimObject(raw); err != nil {
+			return nil, fmt.Errorf("%w: %s must be an object", ErrInvalidClaim, strings.Join(segments[:i+1], claimPathSeparator))
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrMissingClaim, path)
+}
+
+func (c Claims) getValue(path string, out interface{}, kind string) error {
+	raw, err := c.lookup(path)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(raw, out); err != nil || bytes.Equal(raw, []byte("null")) {
+		return fmt.Errorf("%w: %s must be a %s", ErrInvalidClaim, path, kind)
+	}
+
+	return nil
+}
+
+func (c Claims) getNumber(path string) (json.Number, error) {
+	raw, err := c.lookup(path)
+	if err != nil {
+		return "", err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err = decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a number", ErrInvalidClaim, path)
+	}
+
+	return number, nil
+}
+
+// replace sets raw to encoded fields and decodes registered claims from it
+func (c *Claims) replace(fields claimObject) error {
+	raw, err := fields.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var claims Claims
+	if err = claims.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+
+	*c = claims
+	return nil
+}
+
+type claimField struct {
+	key   string
+	value json.RawMessage
+}
+
+// claimObject is JSON object which keeps order of keys and raw values of its members
+type claimObject []claimField
+
+func decodeClaimObject(data []byte) (claimObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("%w: must be an object", ErrInvalidClaim)
+	}
+
+	o := claimObject{}
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		o.set(token.(string), value)
+	}
+
+	if _, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func encodeClaimObject(value interface{}) (claimObject, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeClaimObject(data)
+}
+
+func (o claimObject) MarshalJSON() ([]byte, error) {
+	result := new(bytes.Buffer)
+	result.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Write(key)
+		result.WriteByte(':')
+		result.Write(field.value)
+	}
+	result.WriteByte('}')
+
+	return result.Bytes(), nil
+}
+
+func (o claimObject) get(key string) (json.RawMessage, bool) {
+	for _, field := range o {
+		if field.key == key {
+			return field.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// set replaces value of the key keeping its position or appends new key
+func (o *claimObject) set(key string, value json.RawMessage) {
+	for i, field := range *o {
+		if field.key == key {
+			(*o)[i].value = value
+			return
+		}
+	}
+
+	*o = append(*o, claimField{key: key, value: value})
+}
+
+func (o *claimObject) remove(key string) bool {
+	for i, field := range *o {
+		if field.key == key {
+			*o = append((*o)[:i], (*o)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *claimObject) put(segments []string, value json.RawMessage) error {
+	if len(segments) == 1 {
+		o.set(segments[0], value)
+		return nil
+	}
+
+	nested := claimObject{}
+	if raw, ok := o.get(segments[0]); ok {
+		var err error
+		if nested, err = decodeClaimObject(raw); err != nil {
+			return fmt.Errorf("%w: %s must be an object", ErrInvalidClaim, segments[0])
+		}
+	}
+	if err := nested.put(segments[1:], value); err != nil {
+		return err
+	}
+
+	encoded, err := nested.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	o.set(segments[0], encoded)
+	return nil
+}
+
+func (o *claimObject) delete(segments []string) (bool, error) {
+	raw, ok := o.get(segments[0])
+	if !ok {
+		return false, nil
+	}
+	if len(segments) == 1 {
+		return o.remove(segments[0]), nil
+	}
+
+	nested, err := decodeClaimObject(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be an object", ErrInvalidClaim, segments[0])
+	}
+
+	deleted, err := nested.delete(segments[1:])
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	encoded, err := nested.MarshalJSON()
+	if err != nil {
+		return false, err
+	}
+
+	o.set(segments[0], encoded)
+	return true, nil
+}
@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClaims(t *testing.T, data string) Claims {
+	t.Helper()
+
+	var claims Claims
+	require.NoError(t, json.Unmarshal([]byte(data), &claims))
+	return claims
+}
+
+func TestClaims_Getters(t *testing.T) {
+	claims := newTestClaims(t, `{
+		"sub": "user",
+		"name": "Bear",
+		"age": 7,
+		"ratio": 1.5,
+		"verified": true,
+		"empty": null,
+		"groups": ["a", "b"],
+		"group": "a",
+		"auth_time": 1600000000.25,
+		"address": {"country": "RU", "geo": {"lat": 55}}
+	}`)
+	claims.Issuer = "issuer"
+
+	t.Run("has", func(t *testing.T) {
+		assert.True(t, claims.Has("name"))
+		assert.True(t, claims.Has("iss"))
+		assert.True(t, claims.Has("empty"))
+		assert.True(t, claims.Has("address.geo.lat"))
+		assert.False(t, claims.Has("address.city"))
+		assert.False(t, claims.Has("name.first"))
+		assert.False(t, claims.Has("missing"))
+	})
+	t.Run("keys", func(t *testing.T) {
+		keys, err := claims.Keys()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"address", "age", "auth_time", "empty", "group", "groups", "iss", "name", "ratio", "sub", "verified"}, keys)
+	})
+	t.Run("string", func(t *testing.T) {
+		value, err := claims.GetString("address.country")
+		require.NoError(t, err)
+		assert.Equal(t, "RU", value)
+
+		value, err = claims.GetString("iss")
+		require.NoError(t, err)
+		assert.Equal(t, "issuer", value)
+
+		_, err = claims.GetString("age")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+		_, err = claims.GetString("empty")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+		_, err = claims.GetString("missing")
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+	t.Run("int64", func(t *testing.T) {
+		value, err := claims.GetInt64("age")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), value)
+
+		value, err = claims.GetInt64("address.geo.lat")
+		require.NoError(t, err)
+		assert.Equal(t, int64(55), value)
+
+		_, err = claims.GetInt64("ratio")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+		_, err = claims.GetInt64("name")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+	t.Run("bool", func(t *testing.T) {
+		value, err := claims.GetBool("verified")
+		require.NoError(t, err)
+		assert.True(t, value)
+
+		_, err = claims.GetBool("name")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+	t.Run("strings", func(t *testing.T) {
+		value, err := claims.GetStrings("groups")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, value)
+
+		value, err = claims.GetStrings("group")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, value)
+
+		_, err = claims.GetStrings("age")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+	t.Run("time", func(t *testing.T) {
+		value, err := claims.GetTime("auth_time")
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(1600000000, int64(250*time.Millisecond)), value)
+
+		value, err = claims.GetTime("age")
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(7, 0), value)
+
+		_, err = claims.GetTime("name")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+	t.Run("not an object", func(t *testing.T) {
+		_, err := claims.GetString("name.first")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+}
+
+func TestClaims_Put(t *testing.T) {
+	t.Run("private", func(t *testing.T) {
+		claims := newTestClaims(t, `{"sub":"user","keep":{"x":1}}`)
+		require.NoError(t, claims.Put("name", "Bear"))
+		require.NoError(t, claims.Put("address.geo.lat", 55))
+		require.NoError(t, claims.Put("keep.y", []string{"a"}))
+
+		b, err := json.Marshal(claims)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"sub":"user","name":"Bear","address":{"geo":{"lat":55}},"keep":{"x":1,"y":["a"]}}`, string(b))
+	})
+	t.Run("registered", func(t *testing.T) {
+		claims := Claims{}
+		claims.Subject = "user"
+		require.NoError(t, claims.Put("iss", "issuer"))
+		require.NoError(t, claims.Put("exp", 1600000000))
+
+		assert.Equal(t, "user", claims.Subject)
+		assert.Equal(t, "issuer", claims.Issuer)
+		require.NotNil(t, claims.ExpiresAt)
+		assert.Equal(t, int64(1600000000), claims.ExpiresAt.Unix())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		claims := newTestClaims(t, `{"name":"Bear"}`)
+		err := claims.Put("name.first", "Bear")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+		assert.Error(t, claims.Put("sub", 1))
+		assert.Error(t, claims.Put("name", make(chan int)))
+
+		value, err := claims.GetString("name")
+		require.NoError(t, err)
+		assert.Equal(t, "Bear", value)
+		assert.False(t, claims.Has("sub"))
+	})
+}
+
+func TestClaims_Delete(t *testing.T) {
+	claims := newTestClaims(t, `{"sub":"user","name":"Bear","address":{"country":"RU","city":"Moscow"}}`)
+
+	require.NoError(t, claims.Delete("address.city"))
+	require.NoError(t, claims.Delete("sub"))
+	require.NoError(t, claims.Delete("missing"))
+	require.NoError(t, claims.Delete("address.missing.deep"))
+	assert.True(t, errors.Is(claims.Delete("name.first"), ErrInvalidClaim))
+
+	assert.Empty(t, claims.Subject)
+	b, err := json.Marshal(claims)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Bear","address":{"country":"RU"}}`, string(b))
+}
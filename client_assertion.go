@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// ClientAssertionType is "client_assertion_type" of JWT client authentication (RFC 7523, section 2.2)
+	ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// ClientAssertionTypeParameter is form parameter carrying ClientAssertionType
+	ClientAssertionTypeParameter = "client_assertion_type"
+	// ClientAssertionParameter is form parameter carrying client assertion
+	ClientAssertionParameter = "client_assertion"
+	// ClientIdParameter is optional form parameter carrying client id
+	ClientIdParameter = "client_id"
+
+	// DefaultClientAssertionTTL is lifetime of assertions created by NewClientAssertion
+	DefaultClientAssertionTTL = time.Minute
+)
+
+var (
+	ErrClientMismatch = errors.New("client assertion is issued by another client")
+	ErrNoReplayCache  = errors.New("client assertion requires replay cache")
+	ErrNoAudience     = errors.New("client assertion requires audience")
+)
+
+// NewClientAssertion returns client assertion of clientId (private_key_jwt) for authorization server
+// with tokenEndpoint, signed with registered signer of algorithm a: "iss" and "sub" are clientId,
+// "aud" is tokenEndpoint, "exp" is DefaultClientAssertionTTL after "iat" and "jti" is random.
+// Options may override any of them, e.g. ValidFor or WithTemplateKeyId
+func NewClientAssertion(a alg.Algorithm, clientId, tokenEndpoint string, options ...TemplateOption) (string, error) {
+	options = append([]TemplateOption{
+		IssuedBy(clientId),
+		IssuedFor(tokenEndpoint),
+		ValidFor(DefaultClientAssertionTTL),
+	}, options...)
+
+	return NewTemplate(a, options...).Build().Subject(clientId).WriteString()
+}
+
+// ValidateClientAssertion configures Validator to check assertion of clientId: "iss" and "sub" must be
+// clientId, "aud" must contain one of audiences (token endpoint URL and its aliases, issuer identifier),
+// "exp" and "jti" are required. Assertions are single use (RFC 7523), so PreventReplay with cache is installed.
+// Every assertion is rejected if cache is nil or no audience is given
+func ValidateClientAssertion(clientId string, cache ReplayCache, audiences ...string) ValidatorOption {
+	return func(v *Validator) {
+		WithIssuer(clientId)(v)
+		WithAudience(audiences...)(v)
+		RequireExpiration()(v)
+		WithCheck(func(t Token) error {
+			if cache == nil {
+				return ErrNoReplayCache
+			}
+			if len(audiences) == 0 {
+				return ErrNoAudience
+			}
+
+			return checkClientAssertion(t, clientId)
+		})(v)
+		PreventReplay(cache)(v)
+	}
+}
+
+// ExtractClientAssertion returns client id and assertion from form-encoded token request r.
+// "client_assertion_type" must be ClientAssertionType. If "client_id" parameter is absent,
+// unverified "iss" of the assertion is returned, it must be used only to look up the client key
+func ExtractClientAssertion(r *http.Request) (string, []byte, error) {
+	assertionType, err := FormExtractor(ClientAssertionTypeParameter).Extract(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if string(assertionType) != ClientAssertionType {
+		return "", nil, fmt.Errorf("unsupported client assertion type \"%s\"", assertionType)
+	}
+
+	assertion, err := FormExtractor(ClientAssertionParameter).Extract(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	clientId, err := FormExtractor(ClientIdParameter).Extract(r)
+	switch {
+	case err == nil:
+		return string(clientId), assertion, nil
+	case !errors.Is(err, ErrNoToken):
+		return "", nil, err
+	}
+
+	t, err := Decode(assertion)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(t.Claims.Issuer) == 0 {
+		return "", nil, fmt.Errorf("%w: iss", ErrMissingClaim)
+	}
+
+	return t.Claims.Issuer, assertion, nil
+}
+
+func checkClientAssertion(t Token, clientId string) error {
+	if len(t.Claims.Subject) == 0 {
+		return fmt.Errorf("%w: sub", ErrMissingClaim)
+	}
+	if !isConstTimeEqualsString(t.Claims.Subject, clientId) {
+		return fmt.Errorf("%w: sub \"%s\"", ErrClientMismatch, t.Claims.Subject)
+	}
+	if len(t.Claims.Id) == 0 {
+		return fmt.Errorf("%w: jti", ErrMissingClaim)
+	}
+
+	return nil
+}
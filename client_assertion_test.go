@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientAssertion(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := NewClientAssertion(alg.HS256, "client", "https://as.example.com/token",
+		WithTemplateClock(func() time.Time { return now }), WithTemplateKeyId("key"))
+	require.NoError(t, err)
+
+	token, err := Parse([]byte(s))
+	require.NoError(t, err)
+	assert.Equal(t, "key", token.Header.KeyId)
+	assert.Equal(t, "client", token.Claims.Issuer)
+	assert.Equal(t, "client", token.Claims.Subject)
+	assert.Equal(t, Audience{"https://as.example.com/token"}, token.Claims.Audience)
+	assert.Equal(t, now.Add(DefaultClientAssertionTTL).Unix(), token.Claims.ExpiresAt.Unix())
+	assert.True(t, IsRandomId(token.Claims.Id))
+}
+
+func TestValidateClientAssertion(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	audiences := []string{"https://as.example.com/token", "https://as.example.com"}
+
+	newValidator := func(cache ReplayCache) Validator {
+		return NewValidator(WithClock(clock), ValidateClientAssertion("client", cache, audiences...))
+	}
+	newAssertion := func(t *testing.T, audience string, options ...TemplateOption) Token {
+		options = append([]TemplateOption{WithTemplateClock(clock)}, options...)
+		s, err := NewClientAssertion(alg.HS256, "client", audience, options...)
+		require.NoError(t, err)
+
+		token, err := Parse([]byte(s))
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("aliases", func(t *testing.T) {
+		validator := newValidator(NewMemoryReplayCache(10, WithReplayCacheClock(clock)))
+		for _, audience := range audiences {
+			assert.NoError(t, validator.Validate(newAssertion(t, audience)), audience)
+		}
+
+		err := validator.Validate(newAssertion(t, "https://other.example.com/token"))
+		assert.True(t, errors.Is(err, ErrAudienceMismatch))
+	})
+	t.Run("replay", func(t *testing.T) {
+		validator := newValidator(NewMemoryReplayCache(10, WithReplayCacheClock(clock)))
+		token := newAssertion(t, audiences[0])
+		require.NoError(t, validator.Validate(token))
+		assert.True(t, errors.Is(validator.Validate(token), ErrTokenReplayed))
+	})
+	t.Run("no replay cache", func(t *testing.T) {
+		err := newValidator(nil).Validate(newAssertion(t, audiences[0]))
+		assert.True(t, errors.Is(err, ErrNoReplayCache))
+	})
+	t.Run("no audience", func(t *testing.T) {
+		validator := NewValidator(WithClock(clock),
+			ValidateClientAssertion("client", NewMemoryReplayCache(10, WithReplayCacheClock(clock))))
+		err := validator.Validate(newAssertion(t, "https://other.example.com/token"))
+		assert.True(t, errors.Is(err, ErrNoAudience))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(t *testing.T, token *Token)
+			err    error
+		}{
+			{"issuer", func(t *testing.T, token *Token) { token.Claims.Issuer = "other" }, ErrIssuerMismatch},
+			{"subject", func(t *testing.T, token *Token) { token.Claims.Subject = "other" }, ErrClientMismatch},
+			{"no subject", func(t *testing.T, token *Token) { token.Claims.Subject = "" }, ErrMissingClaim},
+			{"no jti", func(t *testing.T, token *Token) { token.Claims.Id = "" }, ErrMissingClaim},
+			{"no exp", func(t *testing.T, token *Token) { token.Claims.ExpiresAt = nil }, ErrMissingClaim},
+			{"expired", func(t *testing.T, token *Token) {
+				token.Claims.ExpiresAt = NewPosixTime(now.Add(-time.Second))
+			}, ErrTokenExpired},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				token := newAssertion(t, audiences[0])
+				test.modify(t, &token)
+
+				err := newValidator(NewMemoryReplayCache(10, WithReplayCacheClock(clock))).Validate(token)
+				assert.True(t, errors.Is(err, test.err), err)
+			})
+		}
+	})
+}
+
+func TestExtractClientAssertion(t *testing.T) {
+	registerTestHmac(t)
+	assertion, err := NewClientAssertion(alg.HS256, "client", "https://as.example.com/token")
+	require.NoError(t, err)
+
+	newRequest := func(form url.Values) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	t.Run("issuer", func(t *testing.T) {
+		clientId, data, err := ExtractClientAssertion(newRequest(url.Values{
+			"grant_type":                 {"client_credentials"},
+			ClientAssertionTypeParameter: {ClientAssertionType},
+			ClientAssertionParameter:     {assertion},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "client", clientId)
+		assert.Equal(t, assertion, string(data))
+	})
+	t.Run("client_id", func(t *testing.T) {
+		clientId, _, err := ExtractClientAssertion(newRequest(url.Values{
+			ClientIdParameter:            {"other"},
+			ClientAssertionTypeParameter: {ClientAssertionType},
+			ClientAssertionParameter:     {assertion},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "other", clientId)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		forms := map[string]url.Values{
+			"no type":        {ClientAssertionParameter: {assertion}},
+			"wrong type":     {ClientAssertionTypeParameter: {"saml"}, ClientAssertionParameter: {assertion}},
+			"no assertion":   {ClientAssertionTypeParameter: {ClientAssertionType}},
+			"two assertions": {ClientAssertionTypeParameter: {ClientAssertionType}, ClientAssertionParameter: {assertion, assertion}},
+			"garbage":        {ClientAssertionTypeParameter: {ClientAssertionType}, ClientAssertionParameter: {"garbage"}},
+		}
+		for name, form := range forms {
+			_, _, err := ExtractClientAssertion(newRequest(form))
+			assert.Error(t, err, name)
+		}
+	})
+}
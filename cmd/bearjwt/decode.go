@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	jwt "github.com/Viva-Victoria/bear-jwt"
+)
+
+var (
+	stateNames = map[jwt.State]string{
+		jwt.StateValid:     "valid",
+		jwt.StateExpired:   "expired",
+		jwt.StateInactive:  "not active yet",
+		jwt.StateNotIssued: "issued in the future",
+	}
+)
+
+func decodeCommand(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readToken(flags.Args(), in)
+	if err != nil {
+		return err
+	}
+
+	token, err := jwt.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	if err = printToken(out, token); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, "Signature: NOT verified")
+	return err
+}
+
+// readToken returns the token from the first argument or from in
+func readToken(args []string, in io.Reader) ([]byte, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("expected one token, got %d arguments", len(args))
+	}
+	if len(args) == 1 {
+		return []byte(args[0]), nil
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(data), nil
+}
+
+func printToken(out io.Writer, token jwt.Token) error {
+	header, err := json.MarshalIndent(token.Header, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	claims, err := json.Marshal(token.Claims)
+	if err != nil {
+		return err
+	}
+
+	var indented bytes.Buffer
+	if err = json.Indent(&indented, claims, "", "  "); err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(out, "Header:\n%s\nClaims:\n%s\n", header, indented.String()); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	times := []struct {
+		name  string
+		value *jwt.PosixTime
+	}{
+		{"Issued at", token.Claims.IssuedAt},
+		{"Not before", token.Claims.NotBefore},
+		{"Expires at", token.Claims.ExpiresAt},
+	}
+	for _, t := range times {
+		if t.value == nil {
+			continue
+		}
+		if _, err = fmt.Fprintf(out, "%-11s %s\n", t.name+":", humanTime(t.value.Time, now)); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(out, "State:      %s\n", stateNames[token.Validate(now)])
+	return err
+}
+
+func humanTime(t, now time.Time) string {
+	formatted := t.UTC().Format(time.RFC3339)
+	if t.After(now) {
+		return fmt.Sprintf("%s (in %s)", formatted, t.Sub(now).Round(time.Second))
+	}
+
+	return fmt.Sprintf("%s (%s ago)", formatted, now.Sub(t).Round(time.Second))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+func jwksCommand(args []string, _ io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("jwks", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("at least one key file is required")
+	}
+
+	set := alg.JWKSet{Keys: make([]alg.JWK, 0, flags.NArg())}
+	for _, path := range flags.Args() {
+		keys, err := loadKeyFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if key.KeyType == alg.KeyTypeOct {
+				return fmt.Errorf("%s: symmetric keys can not be published", path)
+			}
+
+			key, err = withThumbprintKeyId(key.Public())
+			if err != nil {
+				return err
+			}
+			set.Keys = append(set.Keys, key)
+		}
+	}
+
+	return writeJson(out, set)
+}
+
+func base64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+func keygenCommand(args []string, _ io.Reader, out io.Writer) error {
+	var algorithm, format string
+	var bits int
+
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	flags.StringVar(&algorithm, "alg", "", "algorithm the key is generated for, required")
+	flags.StringVar(&format, "format", "jwk", "output format: jwk or pem")
+	flags.IntVar(&bits, "bits", alg.MinRsaKeySize, "RSA key size")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(algorithm) == 0 {
+		return fmt.Errorf("-alg is required")
+	}
+
+	key, err := generateKey(alg.Algorithm(algorithm), bits)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "jwk":
+		jwk, err := key.JWK()
+		if err != nil {
+			return err
+		}
+		if jwk, err = withThumbprintKeyId(jwk); err != nil {
+			return err
+		}
+
+		return writeJson(out, jwk)
+	case "pem":
+		private, err := key.PrivatePEM()
+		if err != nil {
+			return err
+		}
+
+		public, err := key.PublicPEM()
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(out, "%s%s", private, public)
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func generateKey(a alg.Algorithm, bits int) (alg.GeneratedKey, error) {
+	switch a {
+	case alg.RS256, alg.RS384, alg.RS512, alg.PS256, alg.PS384, alg.PS512:
+		return alg.GenerateRsaKey(a, bits)
+	default:
+		return alg.GenerateKey(a)
+	}
+}
+
+// withThumbprintKeyId sets "kid" to base64url JWK thumbprint when it is empty.
+// Symmetric keys get random "kid", their thumbprint is a hash of the secret
+func withThumbprintKeyId(jwk alg.JWK) (alg.JWK, error) {
+	if len(jwk.KeyId) > 0 {
+		return jwk, nil
+	}
+	if jwk.KeyType == alg.KeyTypeOct {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return alg.JWK{}, err
+		}
+
+		jwk.KeyId = base64URL(id)
+		return jwk, nil
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		return alg.JWK{}, err
+	}
+
+	jwk.KeyId = base64URL(thumbprint)
+	return jwk, nil
+}
+
+func writeJson(out io.Writer, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+// keyFlags are common flags selecting the key of sign and verify commands
+type keyFlags struct {
+	keyFile   string
+	secret    string
+	allowWeak bool
+}
+
+func (f keyFlags) keyOptions() []alg.KeyOption {
+	if f.allowWeak {
+		return []alg.KeyOption{alg.AllowWeakKeys()}
+	}
+
+	return nil
+}
+
+// load returns keys from -key file or -secret value as JWKs
+func (f keyFlags) load() ([]alg.JWK, error) {
+	switch {
+	case len(f.secret) > 0 && len(f.keyFile) > 0:
+		return nil, errors.New("-key and -secret can not be used together")
+	case len(f.secret) > 0:
+		jwk, err := alg.NewJWK([]byte(f.secret))
+		if err != nil {
+			return nil, err
+		}
+
+		return []alg.JWK{jwk}, nil
+	case len(f.keyFile) > 0:
+		return loadKeyFile(f.keyFile)
+	default:
+		return nil, errors.New("-key or -secret is required")
+	}
+}
+
+// loadKeyFile reads PEM (private key, public key or certificate), JWK or JWKS file
+func loadKeyFile(path string) ([]alg.JWK, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		return parsePemKeys(data)
+	}
+
+	var set alg.JWKSet
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(set.Keys) > 0 {
+		return set.Keys, nil
+	}
+
+	var jwk alg.JWK
+	if err = json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(jwk.KeyType) == 0 {
+		return nil, fmt.Errorf("%s: neither JWK nor JWKS", path)
+	}
+
+	return []alg.JWK{jwk}, nil
+}
+
+func parsePemKeys(data []byte) ([]alg.JWK, error) {
+	var keys []alg.JWK
+	seen := make(map[string]int)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, err := parsePemBlock(block)
+		if err != nil {
+			return nil, err
+		}
+
+		jwk, err := alg.NewJWK(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// private and public blocks of the same key are merged into the private one
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := seen[string(thumbprint)]; ok {
+			if jwk.IsPrivate() {
+				keys[i] = jwk
+			}
+			continue
+		}
+
+		seen[string(thumbprint)] = len(keys)
+		keys = append(keys, jwk)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM blocks found")
+	}
+
+	return keys, nil
+}
+
+func parsePemBlock(block *pem.Block) (interface{}, error) {
+	switch {
+	case block.Type == "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return cert.PublicKey, nil
+	case strings.HasSuffix(block.Type, "PUBLIC KEY"):
+		if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+			return key, nil
+		}
+
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+		// block type is not reliable, so every known private key encoding is tried
+		if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("unsupported private key encoding in %q block", block.Type)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// selectKey returns the key with keyId, or the only key when keyId is empty or no key has "kid"
+func selectKey(keys []alg.JWK, keyId string) (alg.JWK, error) {
+	if len(keyId) > 0 {
+		if key, ok := (alg.JWKSet{Keys: keys}).Find(keyId); ok {
+			return key, nil
+		}
+	}
+	if len(keys) == 1 {
+		return keys[0], nil
+	}
+
+	return alg.JWK{}, fmt.Errorf("can not select key with kid %q from %d keys", keyId, len(keys))
+}
+
+func newVerifier(a alg.Algorithm, jwk alg.JWK, options []alg.KeyOption) (alg.Verifier, error) {
+	if err := checkKeyAlgorithm(a, jwk); err != nil {
+		return nil, err
+	}
+	if jwk.KeyType == alg.KeyTypeOct {
+		return newHmac(a, jwk, options)
+	}
+
+	public, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return alg.NewVerifier(a, public, options...)
+}
+
+func newSigner(a alg.Algorithm, jwk alg.JWK, options []alg.KeyOption) (alg.Signer, error) {
+	if err := checkKeyAlgorithm(a, jwk); err != nil {
+		return nil, err
+	}
+
+	private, err := jwk.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return alg.NewSigner(a, private, options...)
+}
+
+func newHmac(a alg.Algorithm, jwk alg.JWK, options []alg.KeyOption) (alg.Verifier, error) {
+	secret, err := jwk.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := alg.NewSigner(a, secret, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, ok := signer.(alg.Verifier)
+	if !ok {
+		return nil, fmt.Errorf("algorithm %s can not verify", a)
+	}
+
+	return verifier, nil
+}
+
+func checkKeyAlgorithm(a alg.Algorithm, jwk alg.JWK) error {
+	if len(jwk.Algorithm) > 0 && jwk.Algorithm != a {
+		return fmt.Errorf("key is intended for %s, not %s", jwk.Algorithm, a)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Command bearjwt decodes, verifies and signs JSON web tokens and generates keys for them.
+//
+// Usage:
+//
+//	bearjwt decode [token]
+//	bearjwt verify -key file | -secret value [-alg alg] [token]
+//	bearjwt sign -alg alg -key file | -secret value [-claims file] [-exp duration]
+//	bearjwt keygen -alg alg [-format jwk|pem] [-bits size]
+//	bearjwt jwks file...
+//
+// Tokens and claims are read from standard input when not passed as arguments.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+var (
+	errUsage = errors.New("usage: bearjwt <decode|verify|sign|keygen|jwks> [flags]")
+)
+
+type command func(args []string, in io.Reader, out io.Writer) error
+
+var commands = map[string]command{
+	"decode": decodeCommand,
+	"verify": verifyCommand,
+	"sign":   signCommand,
+	"keygen": keygenCommand,
+	"jwks":   jwksCommand,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "bearjwt:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return fmt.Errorf("unknown command %q, available: %v", args[0], names)
+	}
+
+	return cmd(args[1:], in, out)
+}
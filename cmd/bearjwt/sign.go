@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	jwt "github.com/Viva-Victoria/bear-jwt"
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+func signCommand(args []string, in io.Reader, out io.Writer) error {
+	var keys keyFlags
+	var algorithm, claimsFile, keyId, typ string
+	var expiresIn time.Duration
+	var issuedAt, thumbprintKeyId bool
+
+	flags := flag.NewFlagSet("sign", flag.ContinueOnError)
+	flags.StringVar(&keys.keyFile, "key", "", "PEM or JWK file with private key")
+	flags.StringVar(&keys.secret, "secret", "", "HMAC secret")
+	flags.BoolVar(&keys.allowWeak, "allow-weak", false, "accept keys weaker than RFC 7518 requires")
+	flags.StringVar(&algorithm, "alg", "", "signature algorithm, required")
+	flags.StringVar(&claimsFile, "claims", "-", "JSON file with claims, - for standard input")
+	flags.StringVar(&keyId, "kid", "", "key id header")
+	flags.BoolVar(&thumbprintKeyId, "thumbprint-kid", false, "set key id header to JWK thumbprint of the asymmetric key")
+	flags.StringVar(&typ, "typ", string(jwt.JsonWebTokenType), "token type header")
+	flags.DurationVar(&expiresIn, "exp", 0, "set expiration time relative to now")
+	flags.BoolVar(&issuedAt, "iat", false, "set issued at time to now")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(algorithm) == 0 {
+		return fmt.Errorf("-alg is required")
+	}
+
+	loaded, err := keys.load()
+	if err != nil {
+		return err
+	}
+
+	key, err := selectKey(loaded, keyId)
+	if err != nil {
+		return err
+	}
+
+	a := alg.Algorithm(algorithm)
+	signer, err := newSigner(a, key, keys.keyOptions())
+	if err != nil {
+		return err
+	}
+
+	claims, err := readClaims(claimsFile, in)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if issuedAt {
+		claims.IssuedAt = jwt.NewPosixTime(now)
+	}
+	if expiresIn != 0 {
+		claims.ExpiresAt = jwt.NewPosixTime(now.Add(expiresIn))
+	}
+
+	verifier, _ := signer.(alg.Verifier)
+	jwt.Register(a, verifier, signer)
+
+	token := jwt.NewToken(a)
+	token.Header.Type = jwt.Type(typ)
+	token.Header.KeyId = keyId
+	token.Claims = claims
+
+	var options []jwt.WriteOption
+	if thumbprintKeyId {
+		options = append(options, jwt.WithThumbprintKeyId())
+	}
+
+	s, err := token.WriteString(options...)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, s)
+	return err
+}
+
+func readClaims(path string, in io.Reader) (jwt.Claims, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(in)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return jwt.Claims{}, err
+	}
+
+	claims := jwt.Claims{}
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return jwt.Claims{}, fmt.Errorf("bad claims: %v", err)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	jwt "github.com/Viva-Victoria/bear-jwt"
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+// keyResolver verifies tokens with one of keys loaded from command line
+type keyResolver struct {
+	keys      []alg.JWK
+	algorithm alg.Algorithm
+	options   []alg.KeyOption
+}
+
+func (r keyResolver) ResolveVerifier(header jwt.Header) (alg.Verifier, error) {
+	if len(r.algorithm) > 0 && r.algorithm != header.Algorithm {
+		return nil, fmt.Errorf("token algorithm %s, expected %s", header.Algorithm, r.algorithm)
+	}
+	if header.Algorithm == alg.None {
+		return nil, errors.New("unsigned tokens can not be verified")
+	}
+
+	key, err := selectKey(r.keys, header.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return newVerifier(header.Algorithm, key, r.options)
+}
+
+func verifyCommand(args []string, in io.Reader, out io.Writer) error {
+	var keys keyFlags
+	var algorithm string
+
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.StringVar(&keys.keyFile, "key", "", "PEM, JWK or JWKS file with verification key")
+	flags.StringVar(&keys.secret, "secret", "", "HMAC secret")
+	flags.BoolVar(&keys.allowWeak, "allow-weak", false, "accept keys weaker than RFC 7518 requires")
+	flags.StringVar(&algorithm, "alg", "", "expected algorithm, taken from the token header if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	loaded, err := keys.load()
+	if err != nil {
+		return err
+	}
+
+	data, err := readToken(flags.Args(), in)
+	if err != nil {
+		return err
+	}
+
+	resolver := keyResolver{keys: loaded, algorithm: alg.Algorithm(algorithm), options: keys.keyOptions()}
+	token, err := jwt.Parse(data, jwt.WithAnyType(), jwt.WithVerifierResolver(resolver))
+	if err != nil {
+		return err
+	}
+
+	if err = printToken(out, token); err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintln(out, "Signature: valid"); err != nil {
+		return err
+	}
+
+	if state := token.ValidateNow(); state != jwt.StateValid {
+		return fmt.Errorf("token is %s", stateNames[state])
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// ConfirmationClaim is "cnf" claim of proof-of-possession tokens (RFC 7800)
+	ConfirmationClaim = "cnf"
+)
+
+var (
+	ErrConfirmationMismatch = errors.New("token is not bound to presented key")
+)
+
+// Confirmation is "cnf" claim binding the token to a key or client certificate.
+// Usually only one member is set
+type Confirmation struct {
+	// JWK is the bound public key (RFC 7800)
+	JWK *alg.JWK `json:"jwk,omitempty"`
+	// JWKThumbprint is base64url SHA-256 JWK thumbprint of the bound key (RFC 9449)
+	JWKThumbprint string `json:"jkt,omitempty"`
+	// X509ThumbprintS256 is base64url SHA-256 thumbprint of the bound client certificate (RFC 8705)
+	X509ThumbprintS256 string `json:"x5t#S256,omitempty"`
+	// KeyId identifies the bound key known to the recipient (RFC 7800), VerifyKey requires JWK or JWKThumbprint with it
+	KeyId string `json:"kid,omitempty"`
+}
+
+// NewKeyConfirmation returns Confirmation with "jkt" thumbprint of key
+func NewKeyConfirmation(key alg.JWK) (Confirmation, error) {
+	thumbprint, err := key.Public().Thumbprint()
+	if err != nil {
+		return Confirmation{}, err
+	}
+
+	return Confirmation{JWKThumbprint: toBase64(thumbprint)}, nil
+}
+
+// NewCertificateConfirmation returns Confirmation with "x5t#S256" thumbprint of client certificate cert
+func NewCertificateConfirmation(cert *x509.Certificate) Confirmation {
+	return Confirmation{X509ThumbprintS256: certificateThumbprint(cert, crypto.SHA256)}
+}
+
+// VerifyKey returns ErrConfirmationMismatch unless "jwk", "jkt" and "kid" members which are set match key.
+// "jwk" or "jkt" must be set: "kid" alone is rejected, as "kid" of the presented key proves nothing
+func (c Confirmation) VerifyKey(key alg.JWK) error {
+	if c.JWK == nil && len(c.JWKThumbprint) == 0 {
+		return fmt.Errorf("%w: cnf has no key", ErrConfirmationMismatch)
+	}
+
+	thumbprint, err := key.Public().Thumbprint()
+	if err != nil {
+		return err
+	}
+	if c.JWK != nil {
+		bound, err := c.JWK.Public().Thumbprint()
+		if err != nil {
+			return err
+		}
+		if !isConstTimeEqualsString(toBase64(bound), toBase64(thumbprint)) {
+			return fmt.Errorf("%w: jwk", ErrConfirmationMismatch)
+		}
+	}
+	if len(c.JWKThumbprint) > 0 && !isConstTimeEqualsString(c.JWKThumbprint, toBase64(thumbprint)) {
+		return fmt.Errorf("%w: jkt", ErrConfirmationMismatch)
+	}
+	if len(c.KeyId) > 0 && !isConstTimeEqualsString(c.KeyId, key.KeyId) {
+		return fmt.Errorf("%w: kid", ErrConfirmationMismatch)
+	}
+
+	return nil
+}
+
+// VerifyCertificate returns ErrConfirmationMismatch unless "x5t#S256" is set and matches cert
+func (c Confirmation) VerifyCertificate(cert *x509.Certificate) error {
+	if len(c.X509ThumbprintS256) == 0 {
+		return fmt.Errorf("%w: cnf has no certificate thumbprint", ErrConfirmationMismatch)
+	}
+	if !isConstTimeEqualsString(c.X509ThumbprintS256, certificateThumbprint(cert, crypto.SHA256)) {
+		return fmt.Errorf("%w: x5t#S256", ErrConfirmationMismatch)
+	}
+
+	return nil
+}
+
+// VerifyConnection checks "x5t#S256" against client certificate of mutual TLS connection state
+func (c Confirmation) VerifyConnection(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: no client certificate", ErrConfirmationMismatch)
+	}
+
+	return c.VerifyCertificate(state.PeerCertificates[0])
+}
+
+// Confirmation returns "cnf" claim
+func (c Claims) Confirmation() (Confirmation, error) {
+	cnf := Confirmation{}
+	if err := c.getValue(ConfirmationClaim, &cnf, "object"); err != nil {
+		return Confirmation{}, err
+	}
+
+	return cnf, nil
+}
+
+// SetConfirmation replaces "cnf" claim
+func (c *Claims) SetConfirmation(cnf Confirmation) error {
+	return c.Put(ConfirmationClaim, cnf)
+}
+
+// RequireKeyBinding requires "cnf" claim matching key presented by the client, see Confirmation.VerifyKey
+func RequireKeyBinding(key alg.JWK) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		cnf, err := t.Claims.Confirmation()
+		if err != nil {
+			return err
+		}
+
+		return cnf.VerifyKey(key)
+	})
+}
+
+// RequireCertificateBinding requires "cnf" claim matching client certificate of mutual TLS connection state
+func RequireCertificateBinding(state tls.ConnectionState) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		cnf, err := t.Claims.Confirmation()
+		if err != nil {
+			return err
+		}
+
+		return cnf.VerifyConnection(state)
+	})
+}
+
+// RequireMutualTLSBinding returns Authorizer checking that the token is bound to client certificate
+// of the request TLS connection (RFC 8705). Middleware rejects unbound tokens with invalid_token error
+func RequireMutualTLSBinding() Authorizer {
+	return func(r *http.Request, t Token) error {
+		if r.TLS == nil {
+			return fmt.Errorf("%w: not a tls connection", ErrConfirmationMismatch)
+		}
+
+		cnf, err := t.Claims.Confirmation()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrConfirmationMismatch, err)
+		}
+
+		return cnf.VerifyConnection(*r.TLS)
+	}
+}
@@ -0,0 +1,180 @@
+package jwt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims_Confirmation(t *testing.T) {
+	key, err := alg.GenerateKey(alg.ES256)
+	require.NoError(t, err)
+	jwk, err := alg.NewJWK(key.PublicKey)
+	require.NoError(t, err)
+
+	claims := Claims{}
+	_, err = claims.Confirmation()
+	assert.True(t, errors.Is(err, ErrMissingClaim))
+
+	require.NoError(t, claims.SetConfirmation(Confirmation{JWK: &jwk, KeyId: "key"}))
+	data, err := claims.MarshalJSON()
+	require.NoError(t, err)
+
+	parsed := Claims{}
+	require.NoError(t, parsed.UnmarshalJSON(data))
+	cnf, err := parsed.Confirmation()
+	require.NoError(t, err)
+	assert.Equal(t, jwk, *cnf.JWK)
+	assert.Equal(t, "key", cnf.KeyId)
+
+	require.NoError(t, parsed.Put(ConfirmationClaim, "key"))
+	_, err = parsed.Confirmation()
+	assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+	token, err := Build(alg.HS256).Confirmation(Confirmation{JWKThumbprint: "jkt"}).Token()
+	require.NoError(t, err)
+	jkt, err := token.Claims.GetString("cnf.jkt")
+	require.NoError(t, err)
+	assert.Equal(t, "jkt", jkt)
+}
+
+func TestConfirmation_VerifyKey(t *testing.T) {
+	key, err := alg.GenerateKey(alg.EdDSA)
+	require.NoError(t, err)
+	private, err := alg.NewJWK(key.PrivateKey)
+	require.NoError(t, err)
+	jwk := private.Public()
+	jwk.KeyId = "key"
+
+	other, err := alg.GenerateKey(alg.EdDSA)
+	require.NoError(t, err)
+	otherJwk, err := alg.NewJWK(other.PublicKey)
+	require.NoError(t, err)
+
+	bound, err := NewKeyConfirmation(private)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cnf  Confirmation
+		key  alg.JWK
+		ok   bool
+	}{
+		{"jkt", bound, jwk, true},
+		{"jkt mismatch", bound, otherJwk, false},
+		{"jwk", Confirmation{JWK: &private}, jwk, true},
+		{"jwk mismatch", Confirmation{JWK: &otherJwk}, jwk, false},
+		{"kid only", Confirmation{KeyId: "key"}, jwk, false},
+		{"jkt and kid", Confirmation{JWKThumbprint: bound.JWKThumbprint, KeyId: "key"}, jwk, true},
+		{"all members must match", Confirmation{JWKThumbprint: bound.JWKThumbprint, KeyId: "other"}, jwk, false},
+		{"certificate", Confirmation{X509ThumbprintS256: bound.JWKThumbprint}, jwk, false},
+		{"empty", Confirmation{}, jwk, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cnf.VerifyKey(test.key)
+			if test.ok {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrConfirmationMismatch), err)
+		})
+	}
+}
+
+func TestConfirmation_VerifyCertificate(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour)
+	client := newTestCertificate(t, "client", nil, x509.KeyUsageDigitalSignature, notAfter)
+	other := newTestCertificate(t, "other", nil, x509.KeyUsageDigitalSignature, notAfter)
+	cnf := NewCertificateConfirmation(client.cert)
+
+	assert.NoError(t, cnf.VerifyCertificate(client.cert))
+	assert.True(t, errors.Is(cnf.VerifyCertificate(other.cert), ErrConfirmationMismatch))
+	assert.True(t, errors.Is(Confirmation{KeyId: "key"}.VerifyCertificate(client.cert), ErrConfirmationMismatch))
+
+	assert.NoError(t, cnf.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{client.cert}}))
+	assert.True(t, errors.Is(cnf.VerifyConnection(tls.ConnectionState{}), ErrConfirmationMismatch))
+}
+
+func TestRequireBinding(t *testing.T) {
+	client := newTestCertificate(t, "client", nil, x509.KeyUsageDigitalSignature, time.Now().Add(time.Hour))
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{client.cert}}
+	jwk, err := alg.NewJWK(&client.key.PublicKey)
+	require.NoError(t, err)
+	keyCnf, err := NewKeyConfirmation(jwk)
+	require.NoError(t, err)
+
+	token := NewToken(alg.HS256)
+	assert.True(t, errors.Is(NewValidator(RequireKeyBinding(jwk)).Validate(token), ErrMissingClaim))
+	assert.True(t, errors.Is(NewValidator(RequireCertificateBinding(state)).Validate(token), ErrMissingClaim))
+
+	require.NoError(t, token.Claims.SetConfirmation(keyCnf))
+	assert.NoError(t, NewValidator(RequireKeyBinding(jwk)).Validate(token))
+	assert.True(t, errors.Is(NewValidator(RequireCertificateBinding(state)).Validate(token), ErrConfirmationMismatch))
+
+	require.NoError(t, token.Claims.SetConfirmation(NewCertificateConfirmation(client.cert)))
+	assert.NoError(t, NewValidator(RequireCertificateBinding(state)).Validate(token))
+	assert.True(t, errors.Is(NewValidator(RequireKeyBinding(jwk)).Validate(token), ErrConfirmationMismatch))
+}
+
+func TestRequireMutualTLSBinding(t *testing.T) {
+	registerTestHmac(t)
+	notAfter := time.Now().Add(time.Hour)
+	client := newTestCertificate(t, "client", nil, x509.KeyUsageDigitalSignature, notAfter)
+	other := newTestCertificate(t, "other", nil, x509.KeyUsageDigitalSignature, notAfter)
+
+	m := NewMiddleware(NewParser(), NewValidator(), WithAuthorizer(RequireMutualTLSBinding()))
+	server := httptest.NewUnstartedServer(m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	send := func(t *testing.T, cert testCertificate, cnf Confirmation) *http.Response {
+		s, err := Build(alg.HS256).Confirmation(cnf).WriteString()
+		require.NoError(t, err)
+
+		transport := server.Client().Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{cert.cert.Raw},
+			PrivateKey:  cert.key,
+		}}
+		defer transport.CloseIdleConnections()
+		httpClient := &http.Client{Transport: transport}
+
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+s)
+
+		response, err := httpClient.Do(request)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		return response
+	}
+
+	response := send(t, client, NewCertificateConfirmation(client.cert))
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+
+	response = send(t, other, NewCertificateConfirmation(client.cert))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, `Bearer error="invalid_token"`, response.Header.Get("WWW-Authenticate"))
+
+	response = send(t, client, Confirmation{JWKThumbprint: "jkt"})
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	t.Run("plain http", func(t *testing.T) {
+		w, _ := serveTestRequest(m, "Bearer "+signTestToken(t, BasicClaims{}))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
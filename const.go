@@ -1,17 +1,50 @@
 package jwt
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 type Type string
 
 const (
 	JsonWebTokenType Type = "JWT"
+	// AccessTokenType is the type of JWT access tokens, RFC 9068
+	AccessTokenType Type = "at+jwt"
+	// DPoPProofType is the type of DPoP proofs, RFC 9449
+	DPoPProofType Type = "dpop+jwt"
+	// SecurityEventType is the type of Security Event Tokens, RFC 8417
+	SecurityEventType Type = "secevent+jwt"
+
+	mediaTypePrefix = "application/"
 )
 
+// Normalize returns the type in canonical form: lower-cased, without media type
+// parameters and without the "application/" prefix, which RFC 7515 allows to omit
+func (t Type) Normalize() Type {
+	s := string(t)
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = s[:i]
+	}
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	if rest := strings.TrimPrefix(s, mediaTypePrefix); !strings.Contains(rest, "/") {
+		s = rest
+	}
+
+	return Type(s)
+}
+
+// Is reports whether both types denote the same media type
+func (t Type) Is(other Type) bool {
+	return t.Normalize() == other.Normalize()
+}
+
 var (
 	ErrNoData             = errors.New("no data")
 	ErrIncorrectFormat    = errors.New("incorrect format")
 	ErrIncorrectSignature = errors.New("incorrect signature")
+	ErrUnsupportedType    = errors.New("token type not supported")
 )
 
 type State int
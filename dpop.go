@@ -0,0 +1,464 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// DPoPHeader is HTTP header carrying DPoP proof
+	DPoPHeader = "DPoP"
+	// DPoPMethodClaim is "htm" claim of DPoP proof, HTTP method of the request
+	DPoPMethodClaim = "htm"
+	// DPoPUriClaim is "htu" claim of DPoP proof, HTTP URI of the request without query and fragment
+	DPoPUriClaim = "htu"
+	// DPoPAccessTokenHashClaim is "ath" claim of DPoP proof, hash of the access token sent with the proof
+	DPoPAccessTokenHashClaim = "ath"
+
+	// DefaultDPoPWindow is the default allowed difference between proof "iat" and current time
+	DefaultDPoPWindow = time.Minute
+	// DefaultDPoPReplayCapacity is the capacity of replay cache created by NewDPoPVerifier,
+	// when it is full the proof id closest to the end of its "iat" window is forgotten
+	DefaultDPoPReplayCapacity = 1 << 16
+)
+
+var (
+	ErrInvalidDPoPProof  = errors.New("invalid dpop proof")
+	ErrDPoPNonceMismatch = errors.New("dpop proof nonce mismatch")
+	ErrDPoPKeyMismatch   = errors.New("access token is not bound to dpop proof key")
+
+	defaultDPoPAlgorithms = []alg.Algorithm{
+		alg.ES256, alg.ES384, alg.ES512,
+		alg.PS256, alg.PS384, alg.PS512,
+		alg.RS256, alg.RS384, alg.RS512,
+		alg.EdDSA,
+	}
+)
+
+// DPoPProofOption configures proof created by NewDPoPProof
+type DPoPProofOption func(p *dpopProofOptions)
+
+type dpopProofOptions struct {
+	clock       func() time.Time
+	accessToken string
+	nonce       string
+}
+
+// WithProofAccessToken adds "ath" hash of accessToken the proof is sent with
+func WithProofAccessToken(accessToken string) DPoPProofOption {
+	return func(p *dpopProofOptions) {
+		p.accessToken = accessToken
+	}
+}
+
+// WithProofNonce adds "nonce" provided by the server in DPoP-Nonce header
+func WithProofNonce(nonce string) DPoPProofOption {
+	return func(p *dpopProofOptions) {
+		p.nonce = nonce
+	}
+}
+
+// WithProofClock replaces time source of "iat", time.Now is used by default
+func WithProofClock(clock func() time.Time) DPoPProofOption {
+	return func(p *dpopProofOptions) {
+		p.clock = clock
+	}
+}
+
+// NewDPoPProof returns DPoP proof (RFC 9449) for the request with method and uri signed by signer of algorithm a.
+// Signer must implement alg.JWKProvider with asymmetric key, its public key is put into "jwk" header.
+// Query and fragment of uri are dropped, the proof gets random "jti"
+func NewDPoPProof(a alg.Algorithm, signer alg.Signer, method, uri string, options ...DPoPProofOption) (string, error) {
+	o := dpopProofOptions{
+		clock: time.Now,
+	}
+	for _, option := range options {
+		option(&o)
+	}
+
+	provider, ok := signer.(alg.JWKProvider)
+	if !ok {
+		return "", fmt.Errorf("signer of algorithm \"%s\" does not provide jwk", a)
+	}
+	jwk, err := provider.JWK()
+	if err != nil {
+		return "", err
+	}
+	if jwk.IsPrivate() {
+		return "", fmt.Errorf("dpop proof requires asymmetric algorithm, got \"%s\"", a)
+	}
+
+	htu, err := normalizeHttpUri(uri)
+	if err != nil {
+		return "", err
+	}
+	id, err := NewRandomId()
+	if err != nil {
+		return "", err
+	}
+
+	t := Token{
+		Header: Header{
+			Algorithm: a,
+			Type:      DPoPProofType,
+			JWK:       &jwk,
+		},
+	}
+	t.Claims.Id = id
+	t.Claims.IssuedAt = NewPosixTime(o.clock())
+
+	claims := [][2]string{
+		{DPoPMethodClaim, method},
+		{DPoPUriClaim, htu},
+	}
+	if len(o.accessToken) > 0 {
+		claims = append(claims, [2]string{DPoPAccessTokenHashClaim, DPoPAccessTokenHash(o.accessToken)})
+	}
+	if len(o.nonce) > 0 {
+		claims = append(claims, [2]string{NonceClaim, o.nonce})
+	}
+	for _, claim := range claims {
+		if err = t.Claims.Put(claim[0], claim[1]); err != nil {
+			return "", err
+		}
+	}
+
+	claimsJson, err := json.Marshal(t.Claims)
+	if err != nil {
+		return "", err
+	}
+	buf, err := sign(t.Header, claimsJson, signer)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// DPoPAccessTokenHash returns "ath" value: base64url SHA-256 hash of accessToken
+func DPoPAccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return toBase64(sum[:])
+}
+
+// DPoPRequest describes HTTP request the proof is presented with
+type DPoPRequest struct {
+	Method string
+	// URI is absolute request URI, query and fragment are ignored
+	URI string
+	// AccessToken is the access token sent with the proof, "ath" is required if it is set
+	AccessToken string
+	// Nonce is the last nonce provided by the server, "nonce" is required if it is set
+	Nonce string
+	// BoundToken is the verified access token, if it is set its "cnf.jkt" must match the proof key,
+	// see DPoPProof.CheckBinding
+	BoundToken *Token
+}
+
+// DPoPProof is verified DPoP proof
+type DPoPProof struct {
+	Token Token
+	// JWK is public key of the proof
+	JWK alg.JWK
+	// Thumbprint is base64url SHA-256 JWK thumbprint of the proof key, "jkt" of bound tokens
+	Thumbprint string
+}
+
+// CheckBinding returns ErrDPoPKeyMismatch unless accessToken "cnf.jkt" claim equals the proof key thumbprint
+func (p DPoPProof) CheckBinding(accessToken Token) error {
+	cnf, err := accessToken.Claims.Confirmation()
+	if err != nil {
+		return err
+	}
+	if len(cnf.JWKThumbprint) == 0 {
+		return fmt.Errorf("%w: cnf.jkt", ErrMissingClaim)
+	}
+	if !isConstTimeEqualsString(cnf.JWKThumbprint, p.Thumbprint) {
+		return ErrDPoPKeyMismatch
+	}
+
+	return nil
+}
+
+// DPoPOption configures DPoPVerifier
+type DPoPOption func(v *DPoPVerifier)
+
+// WithDPoPClock replaces time source used to check "iat", time.Now is used by default
+func WithDPoPClock(clock func() time.Time) DPoPOption {
+	return func(v *DPoPVerifier) {
+		v.clock = clock
+	}
+}
+
+// WithDPoPWindow sets allowed difference between proof "iat" and current time in both directions,
+// DefaultDPoPWindow is used by default
+func WithDPoPWindow(window time.Duration) DPoPOption {
+	return func(v *DPoPVerifier) {
+		v.window = window
+	}
+}
+
+// WithDPoPReplayCache replaces cache of used proof ids. By default, MemoryReplayCache
+// of DefaultDPoPReplayCapacity ids with overflow eviction is created for the verifier, so a flood
+// of valid proofs can not make it reject proofs of other clients
+func WithDPoPReplayCache(cache ReplayCache) DPoPOption {
+	return func(v *DPoPVerifier) {
+		v.replayCache = cache
+	}
+}
+
+// WithDPoPAlgorithms replaces accepted proof algorithms, by default all asymmetric algorithms are accepted
+func WithDPoPAlgorithms(algorithms ...alg.Algorithm) DPoPOption {
+	return func(v *DPoPVerifier) {
+		v.algorithms = algorithms
+	}
+}
+
+// DPoPVerifier verifies DPoP proofs as described in RFC 9449, section 4.3
+type DPoPVerifier struct {
+	parser      Parser
+	clock       func() time.Time
+	window      time.Duration
+	algorithms  []alg.Algorithm
+	replayCache ReplayCache
+}
+
+// NewDPoPVerifier returns DPoPVerifier with applied options
+func NewDPoPVerifier(options ...DPoPOption) DPoPVerifier {
+	v := DPoPVerifier{
+		clock:      time.Now,
+		window:     DefaultDPoPWindow,
+		algorithms: defaultDPoPAlgorithms,
+	}
+	for _, option := range options {
+		option(&v)
+	}
+
+	if v.replayCache == nil {
+		v.replayCache = NewMemoryReplayCache(DefaultDPoPReplayCapacity,
+			WithReplayCacheClock(v.clock),
+			WithReplayCacheOverflowEviction(),
+		)
+	}
+	v.parser = NewParser(RequireType(DPoPProofType), WithVerifierResolver(dpopKeyResolver{algorithms: v.algorithms}))
+
+	return v
+}
+
+// Verify checks proof signature made with "jwk" header key, "htm", "htu", "iat", "ath", "nonce" and
+// binding of the access token against request, then marks proof "jti" as used. Invalid proofs are reported
+// with ErrInvalidDPoPProof, missing or stale nonce with ErrDPoPNonceMismatch, tokens bound to another key
+// with ErrDPoPKeyMismatch and reused proofs with ErrTokenReplayed
+func (v DPoPVerifier) Verify(proof []byte, request DPoPRequest) (DPoPProof, error) {
+	token, err := v.parser.Parse(proof)
+	if err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+
+	p := DPoPProof{
+		Token: token,
+		JWK:   *token.Header.JWK,
+	}
+	thumbprint, err := p.JWK.Thumbprint()
+	if err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	p.Thumbprint = toBase64(thumbprint)
+
+	if err = v.check(token, request); err != nil {
+		return DPoPProof{}, err
+	}
+	if request.BoundToken != nil {
+		if err = p.CheckBinding(*request.BoundToken); err != nil {
+			return DPoPProof{}, err
+		}
+	}
+
+	// the id is recorded only for otherwise valid proofs, so rejected ones do not fill the cache
+
+	ok, err := v.replayCache.Use(p.Thumbprint+"\x00"+token.Claims.Id, token.Claims.IssuedAt.Add(v.window))
+	if err != nil {
+		return DPoPProof{}, err
+	}
+	if !ok {
+		return DPoPProof{}, ErrTokenReplayed
+	}
+
+	return p, nil
+}
+
+// VerifyRequest verifies proof from the single DPoP header of r. Empty request method and URI
+// are taken from r, the URI is built from Host header and TLS state, so set it explicitly behind proxies
+func (v DPoPVerifier) VerifyRequest(r *http.Request, request DPoPRequest) (DPoPProof, error) {
+	proof, err := HeaderExtractor(DPoPHeader).Extract(r)
+	if err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+
+	if len(request.Method) == 0 {
+		request.Method = r.Method
+	}
+	if len(request.URI) == 0 {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		request.URI = scheme + "://" + r.Host + r.URL.EscapedPath()
+	}
+
+	return v.Verify(proof, request)
+}
+
+func (v DPoPVerifier) check(t Token, request DPoPRequest) error {
+	if len(t.Claims.Id) == 0 {
+		return fmt.Errorf("%w: missing jti", ErrInvalidDPoPProof)
+	}
+
+	htm, err := t.Claims.GetString(DPoPMethodClaim)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	if htm != request.Method {
+		return fmt.Errorf("%w: htm \"%s\" does not match request method", ErrInvalidDPoPProof, htm)
+	}
+
+	htu, err := t.Claims.GetString(DPoPUriClaim)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	expected, err := normalizeHttpUri(request.URI)
+	if err != nil {
+		return err
+	}
+	if actual, err := normalizeHttpUri(htu); err != nil || actual != expected {
+		return fmt.Errorf("%w: htu \"%s\" does not match request uri", ErrInvalidDPoPProof, htu)
+	}
+
+	iat := t.Claims.IssuedAt
+	if iat == nil {
+		return fmt.Errorf("%w: missing iat", ErrInvalidDPoPProof)
+	}
+	now := v.clock()
+	if iat.Before(now.Add(-v.window)) || iat.After(now.Add(v.window)) {
+		return fmt.Errorf("%w: iat is outside of accepted window", ErrInvalidDPoPProof)
+	}
+
+	if len(request.Nonce) > 0 {
+		nonce, err := t.Claims.GetString(NonceClaim)
+		if err != nil || !isConstTimeEqualsString(nonce, request.Nonce) {
+			return ErrDPoPNonceMismatch
+		}
+	}
+
+	if len(request.AccessToken) > 0 {
+		ath, err := t.Claims.GetString(DPoPAccessTokenHashClaim)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+		}
+		if !isConstTimeEqualsString(ath, DPoPAccessTokenHash(request.AccessToken)) {
+			return fmt.Errorf("%w: ath does not match access token", ErrInvalidDPoPProof)
+		}
+	}
+
+	return nil
+}
+
+// dpopKeyResolver verifies proofs with the public key from "jwk" header
+type dpopKeyResolver struct {
+	algorithms []alg.Algorithm
+}
+
+func (r dpopKeyResolver) ResolveVerifier(header Header) (alg.Verifier, error) {
+	if !containsAlgorithm(r.algorithms, header.Algorithm) {
+		return nil, fmt.Errorf("algorithm \"%s\" is not accepted", header.Algorithm)
+	}
+
+	if header.JWK == nil {
+		return nil, errors.New("missing jwk header")
+	}
+	if header.JWK.IsPrivate() {
+		return nil, errors.New("jwk header must not contain private key")
+	}
+
+	key, err := header.JWK.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return alg.NewVerifier(header.Algorithm, key)
+}
+
+func containsAlgorithm(algorithms []alg.Algorithm, a alg.Algorithm) bool {
+	for _, item := range algorithms {
+		if item == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeHttpUri returns absolute http(s) uri without query and fragment, with lower case
+// scheme and host, without default port and with normalized percent-encoding of the path
+func normalizeHttpUri(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme != "http" && scheme != "https") || len(u.Host) == 0 {
+		return "", fmt.Errorf("\"%s\" is not absolute http uri", uri)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); len(port) > 0 && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+
+	path := normalizePercentEncoding(u.EscapedPath())
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	return scheme + "://" + host + path, nil
+}
+
+// normalizePercentEncoding decodes percent-encoded unreserved characters and upper cases
+// hex digits of other percent-encoded octets, as described in RFC 3986, section 6.2.2
+func normalizePercentEncoding(path string) string {
+	var result strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' || i+2 >= len(path) || !isHexDigit(path[i+1]) || !isHexDigit(path[i+2]) {
+			result.WriteByte(path[i])
+			continue
+		}
+
+		code, _ := strconv.ParseUint(path[i+1:i+3], 16, 8)
+		if c := byte(code); isUnreservedChar(c) {
+			result.WriteByte(c)
+		} else {
+			result.WriteString(strings.ToUpper(path[i : i+3]))
+		}
+		i += 2
+	}
+
+	return result.String()
+}
+
+func isUnreservedChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,296 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHttpUri(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"https://server.example.com/token", "https://server.example.com/token"},
+		{"HTTPS://Server.Example.COM:443/token?a=b#c", "https://server.example.com/token"},
+		{"http://server.example.com:80", "http://server.example.com/"},
+		{"https://server.example.com:8443/a%2fb/%7Euser", "https://server.example.com:8443/a%2Fb/~user"},
+		{"https://server.example.com/a b/%41", "https://server.example.com/a%20b/A"},
+		{"https://[::1]:443/", "https://[::1]/"},
+	}
+	for _, test := range tests {
+		t.Run(test.uri, func(t *testing.T) {
+			actual, err := normalizeHttpUri(test.uri)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+
+	for _, uri := range []string{"/token", "ftp://server.example.com/", "https://", "%"} {
+		_, err := normalizeHttpUri(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestDPoPProof(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	key, err := alg.GenerateKey(alg.ES256)
+	require.NoError(t, err)
+
+	s, err := NewDPoPProof(alg.ES256, key.Signer, http.MethodPost, "https://server.example.com/token?x=1#f",
+		WithProofClock(clock), WithProofAccessToken("access"), WithProofNonce("nonce"))
+	require.NoError(t, err)
+
+	token, err := Decode([]byte(s))
+	require.NoError(t, err)
+	assert.Equal(t, DPoPProofType, token.Header.Type)
+	require.NotNil(t, token.Header.JWK)
+	assert.False(t, token.Header.JWK.IsPrivate())
+	assert.True(t, IsRandomId(token.Claims.Id))
+	assert.Equal(t, now.Unix(), token.Claims.IssuedAt.Unix())
+
+	for claim, expected := range map[string]string{
+		DPoPMethodClaim:          "POST",
+		DPoPUriClaim:             "https://server.example.com/token",
+		DPoPAccessTokenHashClaim: DPoPAccessTokenHash("access"),
+		NonceClaim:               "nonce",
+	} {
+		value, err := token.Claims.GetString(claim)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, claim)
+	}
+
+	t.Run("symmetric key", func(t *testing.T) {
+		key, err := alg.GenerateKey(alg.HS256)
+		require.NoError(t, err)
+
+		_, err = NewDPoPProof(alg.HS256, key.Signer, http.MethodGet, "https://server.example.com/")
+		assert.Error(t, err)
+	})
+}
+
+func TestDPoPAccessTokenHash(t *testing.T) {
+	// RFC 9449, section 7.1
+	assert.Equal(t, "fUHyO2r2Z3DZ53EsNrWBb0xWXoaNy59IiKCAqksmQEo", DPoPAccessTokenHash("Kz~8mXK1EalYznwH-LC-1fBAo.4Ljp~zsPE_NeO.gxU"))
+}
+
+func TestDPoPVerifier(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	key, err := alg.GenerateKey(alg.ES256)
+	require.NoError(t, err)
+
+	request := DPoPRequest{
+		Method:      http.MethodGet,
+		URI:         "https://resource.example.org/protectedresource",
+		AccessToken: "access",
+		Nonce:       "nonce",
+	}
+	newProof := func(t *testing.T, options ...DPoPProofOption) []byte {
+		options = append([]DPoPProofOption{WithProofClock(clock), WithProofAccessToken("access"), WithProofNonce("nonce")}, options...)
+		s, err := NewDPoPProof(alg.ES256, key.Signer, http.MethodGet, "https://Resource.Example.org:443/protectedresource", options...)
+		require.NoError(t, err)
+		return []byte(s)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		verifier := NewDPoPVerifier(WithDPoPClock(clock))
+		proof, err := verifier.Verify(newProof(t), request)
+		require.NoError(t, err)
+
+		jwk, err := key.Signer.(alg.JWKProvider).JWK()
+		require.NoError(t, err)
+		thumbprint, err := jwk.Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, toBase64(thumbprint), proof.Thumbprint)
+		assert.Equal(t, jwk, proof.JWK)
+	})
+	t.Run("replay", func(t *testing.T) {
+		verifier := NewDPoPVerifier(WithDPoPClock(clock))
+		proof := newProof(t)
+		_, err := verifier.Verify(proof, request)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(proof, request)
+		assert.True(t, errors.Is(err, ErrTokenReplayed))
+	})
+	t.Run("default replay cache evicts on overflow", func(t *testing.T) {
+		cache, ok := NewDPoPVerifier().replayCache.(*MemoryReplayCache)
+		require.True(t, ok)
+		assert.True(t, cache.evictOnOverflow)
+		assert.Equal(t, DefaultDPoPReplayCapacity, cache.capacity)
+	})
+	t.Run("bound token", func(t *testing.T) {
+		jwk, err := key.Signer.(alg.JWKProvider).JWK()
+		require.NoError(t, err)
+		thumbprint, err := jwk.Thumbprint()
+		require.NoError(t, err)
+
+		bound := NewToken(alg.HS256)
+		require.NoError(t, bound.Claims.Put("cnf.jkt", "other"))
+
+		verifier := NewDPoPVerifier(WithDPoPClock(clock))
+		proof := newProof(t)
+		boundRequest := request
+		boundRequest.BoundToken = &bound
+		_, err = verifier.Verify(proof, boundRequest)
+		assert.True(t, errors.Is(err, ErrDPoPKeyMismatch), err)
+
+		require.NoError(t, bound.Claims.Put("cnf.jkt", toBase64(thumbprint)))
+		_, err = verifier.Verify(proof, boundRequest)
+		assert.NoError(t, err)
+	})
+	t.Run("rejected proof is not recorded", func(t *testing.T) {
+		verifier := NewDPoPVerifier(WithDPoPClock(clock))
+		proof := newProof(t)
+		_, err := verifier.Verify(proof, DPoPRequest{Method: http.MethodPost, URI: request.URI})
+		assert.True(t, errors.Is(err, ErrInvalidDPoPProof))
+
+		_, err = verifier.Verify(proof, request)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			proof   []byte
+			request DPoPRequest
+			err     error
+		}{
+			{"method", newProof(t), DPoPRequest{Method: "POST", URI: request.URI}, ErrInvalidDPoPProof},
+			{"uri", newProof(t), DPoPRequest{Method: "GET", URI: "https://resource.example.org/other"}, ErrInvalidDPoPProof},
+			{"old", newProof(t, WithProofClock(func() time.Time { return now.Add(-2 * time.Minute) })), request, ErrInvalidDPoPProof},
+			{"future", newProof(t, WithProofClock(func() time.Time { return now.Add(2 * time.Minute) })), request, ErrInvalidDPoPProof},
+			{"access token", newProof(t, WithProofAccessToken("other")), request, ErrInvalidDPoPProof},
+			{"nonce", newProof(t, WithProofNonce("stale")), request, ErrDPoPNonceMismatch},
+			{"garbage", []byte("garbage"), request, ErrInvalidDPoPProof},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := NewDPoPVerifier(WithDPoPClock(clock)).Verify(test.proof, test.request)
+				assert.True(t, errors.Is(err, test.err), err)
+			})
+		}
+	})
+	t.Run("missing ath and nonce", func(t *testing.T) {
+		s, err := NewDPoPProof(alg.ES256, key.Signer, http.MethodGet, request.URI, WithProofClock(clock))
+		require.NoError(t, err)
+
+		verifier := NewDPoPVerifier(WithDPoPClock(clock))
+		_, err = verifier.Verify([]byte(s), DPoPRequest{Method: request.Method, URI: request.URI, Nonce: "nonce"})
+		assert.True(t, errors.Is(err, ErrDPoPNonceMismatch))
+		_, err = verifier.Verify([]byte(s), DPoPRequest{Method: request.Method, URI: request.URI, AccessToken: "access"})
+		assert.True(t, errors.Is(err, ErrInvalidDPoPProof))
+		_, err = verifier.Verify([]byte(s), DPoPRequest{Method: request.Method, URI: request.URI})
+		assert.NoError(t, err)
+	})
+	t.Run("window", func(t *testing.T) {
+		proof := newProof(t, WithProofClock(func() time.Time { return now.Add(-2 * time.Minute) }))
+		_, err := NewDPoPVerifier(WithDPoPClock(clock), WithDPoPWindow(5*time.Minute)).Verify(proof, request)
+		assert.NoError(t, err)
+	})
+	t.Run("algorithms", func(t *testing.T) {
+		_, err := NewDPoPVerifier(WithDPoPClock(clock), WithDPoPAlgorithms(alg.EdDSA)).Verify(newProof(t), request)
+		assert.True(t, errors.Is(err, ErrInvalidDPoPProof))
+	})
+	t.Run("forged key", func(t *testing.T) {
+		other, err := alg.GenerateKey(alg.ES256)
+		require.NoError(t, err)
+		jwk, err := other.Signer.(alg.JWKProvider).JWK()
+		require.NoError(t, err)
+
+		token, err := Decode(newProof(t))
+		require.NoError(t, err)
+		token.Header.JWK = &jwk
+		claimsJson, err := token.Claims.MarshalJSON()
+		require.NoError(t, err)
+		forged, err := sign(token.Header, claimsJson, key.Signer)
+		require.NoError(t, err)
+
+		_, err = NewDPoPVerifier(WithDPoPClock(clock)).Verify(forged.Bytes(), request)
+		assert.True(t, errors.Is(err, ErrInvalidDPoPProof))
+	})
+	t.Run("private key", func(t *testing.T) {
+		jwk, err := alg.NewJWK(key.PrivateKey)
+		require.NoError(t, err)
+
+		token, err := Decode(newProof(t))
+		require.NoError(t, err)
+		token.Header.JWK = &jwk
+		claimsJson, err := token.Claims.MarshalJSON()
+		require.NoError(t, err)
+		proof, err := sign(token.Header, claimsJson, key.Signer)
+		require.NoError(t, err)
+
+		_, err = NewDPoPVerifier(WithDPoPClock(clock)).Verify(proof.Bytes(), request)
+		assert.True(t, errors.Is(err, ErrInvalidDPoPProof))
+	})
+}
+
+func TestDPoPProof_CheckBinding(t *testing.T) {
+	key, err := alg.GenerateKey(alg.EdDSA)
+	require.NoError(t, err)
+	s, err := NewDPoPProof(alg.EdDSA, key.Signer, http.MethodGet, "https://resource.example.org/")
+	require.NoError(t, err)
+	proof, err := NewDPoPVerifier().Verify([]byte(s), DPoPRequest{Method: http.MethodGet, URI: "https://resource.example.org"})
+	require.NoError(t, err)
+
+	token := NewToken(alg.HS256)
+	assert.True(t, errors.Is(proof.CheckBinding(token), ErrMissingClaim))
+
+	require.NoError(t, token.Claims.Put("cnf.jkt", proof.Thumbprint))
+	assert.NoError(t, proof.CheckBinding(token))
+
+	require.NoError(t, token.Claims.Put("cnf.jkt", "other"))
+	assert.True(t, errors.Is(proof.CheckBinding(token), ErrDPoPKeyMismatch))
+}
+
+func TestDPoPVerifier_VerifyRequest(t *testing.T) {
+	key, err := alg.GenerateKey(alg.ES256)
+	require.NoError(t, err)
+	verifier := NewDPoPVerifier()
+
+	var proofErr error
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "DPoP ")
+		_, proofErr = verifier.VerifyRequest(r, DPoPRequest{AccessToken: accessToken})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	send := func(t *testing.T, proofs ...string) {
+		request, err := http.NewRequest(http.MethodPut, server.URL+"/resource?id=1", nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "DPoP access")
+		for _, proof := range proofs {
+			request.Header.Add(DPoPHeader, proof)
+		}
+
+		response, err := server.Client().Do(request)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+	}
+
+	proof, err := NewDPoPProof(alg.ES256, key.Signer, http.MethodPut, server.URL+"/resource", WithProofAccessToken("access"))
+	require.NoError(t, err)
+	send(t, proof)
+	assert.NoError(t, proofErr)
+
+	send(t)
+	assert.True(t, errors.Is(proofErr, ErrInvalidDPoPProof))
+
+	send(t, proof, proof)
+	assert.True(t, errors.Is(proofErr, ErrInvalidDPoPProof))
+}
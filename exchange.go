@@ -0,0 +1,190 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// ActorClaim is "act" claim of exchanged tokens, the party acting on behalf of the subject (RFC 8693)
+	ActorClaim = "act"
+	// MayActClaim is "may_act" claim, the party allowed to act on behalf of the subject (RFC 8693)
+	MayActClaim = "may_act"
+
+	// TokenExchangeGrantType is "grant_type" of token exchange requests
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// AccessTokenTypeURI is token type identifier of access tokens in token exchange
+	AccessTokenTypeURI = "urn:ietf:params:oauth:token-type:access_token"
+	// JwtTokenTypeURI is token type identifier of JWTs in token exchange
+	JwtTokenTypeURI = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+var (
+	ErrActorNotAllowed   = errors.New("actor is not allowed")
+	ErrActorChainTooLong = errors.New("actor chain is too long")
+)
+
+// Actor is "act" or "may_act" claim identifying a party by "sub" and optional "iss".
+// Nested Actor of "act" is the prior actor of delegation chain
+type Actor struct {
+	Subject string `json:"sub,omitempty"`
+	Issuer  string `json:"iss,omitempty"`
+	Actor   *Actor `json:"act,omitempty"`
+}
+
+// Chain returns the actor followed by prior actors, nested actors are not set in the result
+func (a Actor) Chain() []Actor {
+	chain := make([]Actor, 0, 1)
+	for current := &a; current != nil; current = current.Actor {
+		chain = append(chain, Actor{Subject: current.Subject, Issuer: current.Issuer})
+	}
+
+	return chain
+}
+
+// Matches reports whether the actor has "sub" of pattern and its "iss" if pattern has one
+func (a Actor) Matches(pattern Actor) bool {
+	return a.Subject == pattern.Subject && (len(pattern.Issuer) == 0 || a.Issuer == pattern.Issuer)
+}
+
+// Actor returns "act" claim
+func (c Claims) Actor() (Actor, error) {
+	return c.getActor(ActorClaim)
+}
+
+// SetActor replaces "act" claim
+func (c *Claims) SetActor(actor Actor) error {
+	return c.Put(ActorClaim, actor)
+}
+
+// MayAct returns "may_act" claim
+func (c Claims) MayAct() (Actor, error) {
+	return c.getActor(MayActClaim)
+}
+
+// SetMayAct replaces "may_act" claim
+func (c *Claims) SetMayAct(actor Actor) error {
+	return c.Put(MayActClaim, actor)
+}
+
+// Exchange returns Builder of token issued for actor in exchange of subjectToken: "sub" is taken
+// from subjectToken and "act" is actor with "act" of subjectToken as prior actor, so the delegation
+// chain is preserved with all its members. If subjectToken has "may_act", actor must match it, otherwise
+// the builder fails with ErrActorNotAllowed. The builder fails with ErrMissingClaim if subjectToken has no "sub".
+// Claims of subjectToken are trusted as is, so it must be already parsed and validated
+func (t Template) Exchange(subjectToken Token, actor Actor) *Builder {
+	b := t.Build().Subject(subjectToken.Claims.Subject)
+	if len(subjectToken.Claims.Subject) == 0 {
+		b.err = fmt.Errorf("%w: subject token has no sub", ErrMissingClaim)
+		return b
+	}
+
+	mayAct, err := subjectToken.Claims.MayAct()
+	switch {
+	case errors.Is(err, ErrMissingClaim):
+	case err != nil:
+		b.err = err
+		return b
+	case !actor.Matches(mayAct):
+		b.err = fmt.Errorf("%w: \"%s\" may not act for \"%s\"", ErrActorNotAllowed, actor.Subject, subjectToken.Claims.Subject)
+		return b
+	}
+
+	actor.Actor = nil
+	_, err = subjectToken.Claims.Actor()
+	switch {
+	case errors.Is(err, ErrMissingClaim):
+		return b.Actor(actor)
+	case err != nil:
+		b.err = err
+		return b
+	}
+
+	prior, err := subjectToken.Claims.lookup(ActorClaim)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.Claim(ActorClaim, delegatedActor{Subject: actor.Subject, Issuer: actor.Issuer, Actor: prior})
+}
+
+// delegatedActor is "act" claim with the prior actor kept as raw JSON,
+// so its members unknown to Actor (e.g. "client_id") are not lost
+type delegatedActor struct {
+	Subject string          `json:"sub,omitempty"`
+	Issuer  string          `json:"iss,omitempty"`
+	Actor   json.RawMessage `json:"act"`
+}
+
+// LimitActorChain rejects tokens with more than depth actors in "act" claim
+func LimitActorChain(depth int) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		chain, err := actorChain(t)
+		if err != nil {
+			return err
+		}
+		if len(chain) > depth {
+			return fmt.Errorf("%w: %d actors", ErrActorChainTooLong, len(chain))
+		}
+
+		return nil
+	})
+}
+
+// AllowActors rejects tokens with actors of "act" claim, including prior ones, not matching
+// any of allowed, see Actor.Matches. Tokens without "act" are accepted
+func AllowActors(allowed ...Actor) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		chain, err := actorChain(t)
+		if err != nil {
+			return err
+		}
+
+		for _, actor := range chain {
+			if !matchesAnyActor(actor, allowed) {
+				return fmt.Errorf("%w: \"%s\"", ErrActorNotAllowed, actor.Subject)
+			}
+		}
+
+		return nil
+	})
+}
+
+func actorChain(t Token) ([]Actor, error) {
+	actor, err := t.Claims.Actor()
+	switch {
+	case errors.Is(err, ErrMissingClaim):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return actor.Chain(), nil
+	}
+}
+
+func matchesAnyActor(actor Actor, patterns []Actor) bool {
+	for _, pattern := range patterns {
+		if actor.Matches(pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Claims) getActor(path string) (Actor, error) {
+	actor := Actor{}
+	if err := c.getValue(path, &actor, "object"); err != nil {
+		return Actor{}, err
+	}
+
+	for current := &actor; current != nil; current = current.Actor {
+		if len(current.Subject) == 0 {
+			return Actor{}, fmt.Errorf("%w: %s must have sub", ErrInvalidClaim, path)
+		}
+	}
+
+	return actor, nil
+}
@@ -0,0 +1,169 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims_Actor(t *testing.T) {
+	claims := newTestClaims(t, `{"sub":"user","act":{"sub":"gateway","iss":"https://auth.example.com","act":{"sub":"frontend"}}}`)
+	actor, err := claims.Actor()
+	require.NoError(t, err)
+	assert.Equal(t, []Actor{
+		{Subject: "gateway", Issuer: "https://auth.example.com"},
+		{Subject: "frontend"},
+	}, actor.Chain())
+
+	_, err = claims.MayAct()
+	assert.True(t, errors.Is(err, ErrMissingClaim))
+
+	require.NoError(t, claims.SetMayAct(Actor{Subject: "admin"}))
+	mayAct, err := claims.MayAct()
+	require.NoError(t, err)
+	assert.Equal(t, Actor{Subject: "admin"}, mayAct)
+
+	for _, data := range []string{`{"act":"gateway"}`, `{"act":{}}`, `{"act":{"sub":"a","act":{"iss":"b"}}}`} {
+		_, err := newTestClaims(t, data).Actor()
+		assert.True(t, errors.Is(err, ErrInvalidClaim), data)
+	}
+}
+
+func TestActor_Matches(t *testing.T) {
+	actor := Actor{Subject: "gateway", Issuer: "https://auth.example.com"}
+	assert.True(t, actor.Matches(Actor{Subject: "gateway"}))
+	assert.True(t, actor.Matches(Actor{Subject: "gateway", Issuer: "https://auth.example.com"}))
+	assert.False(t, actor.Matches(Actor{Subject: "gateway", Issuer: "https://other.example.com"}))
+	assert.False(t, actor.Matches(Actor{Subject: "other"}))
+	assert.False(t, Actor{Subject: "gateway"}.Matches(actor))
+}
+
+func TestTemplate_Exchange(t *testing.T) {
+	registerTestHmac(t)
+	template := NewTemplate(alg.HS256, IssuedBy("https://auth.example.com"), IssuedFor("https://backend.example.com"),
+		ValidFor(time.Minute))
+	gateway := Actor{Subject: "gateway"}
+
+	user, err := template.Build().Subject("user").Token()
+	require.NoError(t, err)
+
+	t.Run("first exchange", func(t *testing.T) {
+		token, err := template.Exchange(user, gateway).Token()
+		require.NoError(t, err)
+		assert.Equal(t, "user", token.Claims.Subject)
+		assert.Equal(t, Audience{"https://backend.example.com"}, token.Claims.Audience)
+
+		actor, err := token.Claims.Actor()
+		require.NoError(t, err)
+		assert.Equal(t, gateway, actor)
+	})
+	t.Run("chain is preserved", func(t *testing.T) {
+		first, err := template.Exchange(user, gateway).Token()
+		require.NoError(t, err)
+		s, err := template.Exchange(first, Actor{Subject: "orders", Actor: &Actor{Subject: "ignored"}}).WriteString()
+		require.NoError(t, err)
+
+		second, err := Parse([]byte(s))
+		require.NoError(t, err)
+		actor, err := second.Claims.Actor()
+		require.NoError(t, err)
+		assert.Equal(t, []Actor{{Subject: "orders"}, {Subject: "gateway"}}, actor.Chain())
+	})
+	t.Run("prior actor members are preserved", func(t *testing.T) {
+		token := user
+		require.NoError(t, token.Claims.Put(ActorClaim, map[string]interface{}{
+			"sub":       "gateway",
+			"client_id": "gateway-client",
+			"act":       map[string]interface{}{"sub": "frontend", "scope": "read"},
+		}))
+		s, err := template.Exchange(token, Actor{Subject: "orders", Issuer: "https://auth.example.com"}).WriteString()
+		require.NoError(t, err)
+
+		exchanged, err := Parse([]byte(s))
+		require.NoError(t, err)
+		data, err := exchanged.Claims.MarshalJSON()
+		require.NoError(t, err)
+
+		var claims struct {
+			Actor json.RawMessage `json:"act"`
+		}
+		require.NoError(t, json.Unmarshal(data, &claims))
+		assert.JSONEq(t, `{"sub":"orders","iss":"https://auth.example.com","act":{
+			"sub":"gateway","client_id":"gateway-client","act":{"sub":"frontend","scope":"read"}
+		}}`, string(claims.Actor))
+	})
+	t.Run("no subject", func(t *testing.T) {
+		anonymous, err := template.Build().Token()
+		require.NoError(t, err)
+
+		_, err = template.Exchange(anonymous, gateway).Token()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+	t.Run("may_act", func(t *testing.T) {
+		restricted, err := template.Build().Subject("user").MayAct(Actor{Subject: "gateway", Issuer: "https://auth.example.com"}).Token()
+		require.NoError(t, err)
+
+		_, err = template.Exchange(restricted, Actor{Subject: "gateway", Issuer: "https://auth.example.com"}).Token()
+		assert.NoError(t, err)
+		_, err = template.Exchange(restricted, gateway).Token()
+		assert.True(t, errors.Is(err, ErrActorNotAllowed))
+		_, err = template.Exchange(restricted, Actor{Subject: "other"}).WriteString()
+		assert.True(t, errors.Is(err, ErrActorNotAllowed))
+	})
+	t.Run("malformed subject token", func(t *testing.T) {
+		token := user
+		require.NoError(t, token.Claims.Put(ActorClaim, "gateway"))
+		_, err := template.Exchange(token, gateway).Token()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+		token = user
+		require.NoError(t, token.Claims.Put(MayActClaim, 1))
+		_, err = template.Exchange(token, gateway).Token()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+}
+
+func TestActorPolicies(t *testing.T) {
+	token := NewToken(alg.HS256)
+	require.NoError(t, token.Claims.SetActor(Actor{
+		Subject: "orders",
+		Actor:   &Actor{Subject: "gateway", Issuer: "https://auth.example.com"},
+	}))
+
+	tests := []struct {
+		name   string
+		option ValidatorOption
+		err    error
+	}{
+		{"depth", LimitActorChain(2), nil},
+		{"too deep", LimitActorChain(1), ErrActorChainTooLong},
+		{"allowed", AllowActors(Actor{Subject: "orders"}, Actor{Subject: "gateway", Issuer: "https://auth.example.com"}), nil},
+		{"prior actor is not allowed", AllowActors(Actor{Subject: "orders"}), ErrActorNotAllowed},
+		{"issuer is not allowed", AllowActors(Actor{Subject: "orders"}, Actor{Subject: "gateway", Issuer: "https://other.example.com"}), ErrActorNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewValidator(test.option).Validate(token)
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.err), err)
+		})
+	}
+
+	t.Run("no actor", func(t *testing.T) {
+		plain := NewToken(alg.HS256)
+		assert.NoError(t, NewValidator(LimitActorChain(0), AllowActors()).Validate(plain))
+
+		require.NoError(t, plain.Claims.Put(ActorClaim, []string{"gateway"}))
+		assert.True(t, errors.Is(NewValidator(LimitActorChain(1)).Validate(plain), ErrInvalidClaim))
+	})
+}
@@ -0,0 +1,165 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// AccessTokenParameter is the name of query and form parameter carrying token, RFC 6750
+	AccessTokenParameter = "access_token"
+)
+
+var (
+	ErrAmbiguousToken = errors.New("request has more than one token")
+)
+
+// ExtractorFunc is a function implementing Extractor
+type ExtractorFunc func(r *http.Request) ([]byte, error)
+
+// Extract calls f(r)
+func (f ExtractorFunc) Extract(r *http.Request) ([]byte, error) {
+	return f(r)
+}
+
+// AuthorizationExtractor extracts token from "Authorization: <scheme> <token>" header.
+// Scheme is compared case-insensitively, e.g. "Bearer" or "DPoP"
+func AuthorizationExtractor(scheme string) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		values := r.Header.Values("Authorization")
+		if len(values) == 0 {
+			return nil, ErrNoToken
+		}
+		if len(values) > 1 {
+			return nil, ErrAmbiguousToken
+		}
+
+		parts := strings.SplitN(values[0], " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], scheme) {
+			return nil, ErrNoToken
+		}
+
+		return nonEmptyToken(strings.TrimSpace(parts[1]), scheme+" authorization")
+	})
+}
+
+// BearerExtractor extracts token from "Authorization: Bearer <token>" header, RFC 6750 section 2.1
+func BearerExtractor() Extractor {
+	return AuthorizationExtractor(bearerScheme)
+}
+
+// HeaderExtractor extracts the whole value of header name as token, e.g. "X-Access-Token"
+func HeaderExtractor(name string) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		values := r.Header.Values(name)
+		if len(values) == 0 {
+			return nil, ErrNoToken
+		}
+		if len(values) > 1 {
+			return nil, ErrAmbiguousToken
+		}
+
+		return nonEmptyToken(strings.TrimSpace(values[0]), name+" header")
+	})
+}
+
+// CookieExtractor extracts token from cookie name
+func CookieExtractor(name string) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		found := 0
+		value := ""
+		for _, cookie := range r.Cookies() {
+			if cookie.Name == name {
+				found++
+				value = cookie.Value
+			}
+		}
+
+		switch found {
+		case 0:
+			return nil, ErrNoToken
+		case 1:
+			return nonEmptyToken(value, name+" cookie")
+		default:
+			return nil, ErrAmbiguousToken
+		}
+	})
+}
+
+// QueryExtractor extracts token from URI query parameter name, RFC 6750 section 2.3 uses AccessTokenParameter
+func QueryExtractor(name string) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		return singleValue(r.URL.Query()[name], name+" query parameter")
+	})
+}
+
+// FormExtractor extracts token from form-encoded body parameter name, RFC 6750 section 2.2 uses AccessTokenParameter.
+// Only requests with "application/x-www-form-urlencoded" body are inspected
+func FormExtractor(name string) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		if r.Body == nil || r.Method == http.MethodGet {
+			return nil, ErrNoToken
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/x-www-form-urlencoded" {
+			return nil, ErrNoToken
+		}
+
+		if err = r.ParseForm(); err != nil {
+			return nil, fmt.Errorf("bad form: %v", err)
+		}
+
+		return singleValue(r.PostForm[name], name+" form parameter")
+	})
+}
+
+// ChainExtractors tries extractors in order and returns the first found token.
+// If several extractors find a token, ErrAmbiguousToken is returned, as RFC 6750 forbids
+// to use more than one method to transmit the token
+func ChainExtractors(extractors ...Extractor) Extractor {
+	return ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		var token []byte
+		for _, extractor := range extractors {
+			found, err := extractor.Extract(r)
+			if errors.Is(err, ErrNoToken) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if token != nil {
+				return nil, ErrAmbiguousToken
+			}
+
+			token = found
+		}
+		if token == nil {
+			return nil, ErrNoToken
+		}
+
+		return token, nil
+	})
+}
+
+func singleValue(values []string, source string) ([]byte, error) {
+	switch len(values) {
+	case 0:
+		return nil, ErrNoToken
+	case 1:
+		return nonEmptyToken(values[0], source)
+	default:
+		return nil, ErrAmbiguousToken
+	}
+}
+
+func nonEmptyToken(token, source string) ([]byte, error) {
+	if len(token) == 0 {
+		return nil, fmt.Errorf("empty token in %s", source)
+	}
+
+	return []byte(token), nil
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExtract(t *testing.T, extractor Extractor, r *http.Request, expected string, expectedErr error) {
+	t.Helper()
+
+	token, err := extractor.Extract(r)
+	if expectedErr != nil {
+		require.True(t, errors.Is(err, expectedErr), "unexpected error: %v", err)
+		return
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(token))
+}
+
+func TestAuthorizationExtractor(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	testExtract(t, BearerExtractor(), r, "", ErrNoToken)
+
+	r.Header.Set("Authorization", "BEARER  token-value ")
+	testExtract(t, BearerExtractor(), r, "token-value", nil)
+	testExtract(t, AuthorizationExtractor("DPoP"), r, "", ErrNoToken)
+
+	r.Header.Set("Authorization", "DPoP proof-bound")
+	testExtract(t, AuthorizationExtractor("dpop"), r, "proof-bound", nil)
+
+	r.Header.Set("Authorization", "Bearer")
+	testExtract(t, BearerExtractor(), r, "", ErrNoToken)
+
+	r.Header.Set("Authorization", "Bearer  ")
+	_, err := BearerExtractor().Extract(r)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrNoToken))
+
+	r.Header.Set("Authorization", "Bearer first")
+	r.Header.Add("Authorization", "Bearer second")
+	testExtract(t, BearerExtractor(), r, "", ErrAmbiguousToken)
+}
+
+func TestHeaderExtractor(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	testExtract(t, HeaderExtractor("X-Access-Token"), r, "", ErrNoToken)
+
+	r.Header.Set("X-Access-Token", "value")
+	testExtract(t, HeaderExtractor("X-Access-Token"), r, "value", nil)
+
+	r.Header.Add("X-Access-Token", "other")
+	testExtract(t, HeaderExtractor("X-Access-Token"), r, "", ErrAmbiguousToken)
+}
+
+func TestCookieExtractor(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	testExtract(t, CookieExtractor("session"), r, "", ErrNoToken)
+
+	r.AddCookie(&http.Cookie{Name: "session", Value: "value"})
+	r.AddCookie(&http.Cookie{Name: "other", Value: "other"})
+	testExtract(t, CookieExtractor("session"), r, "value", nil)
+
+	r.AddCookie(&http.Cookie{Name: "session", Value: "second"})
+	testExtract(t, CookieExtractor("session"), r, "", ErrAmbiguousToken)
+}
+
+func TestQueryExtractor(t *testing.T) {
+	extractor := QueryExtractor(AccessTokenParameter)
+
+	testExtract(t, extractor, httptest.NewRequest(http.MethodGet, "/", nil), "", ErrNoToken)
+	testExtract(t, extractor, httptest.NewRequest(http.MethodGet, "/?access_token=value", nil), "value", nil)
+	testExtract(t, extractor, httptest.NewRequest(http.MethodGet, "/?access_token=a&access_token=b", nil), "", ErrAmbiguousToken)
+}
+
+func TestFormExtractor(t *testing.T) {
+	extractor := FormExtractor(AccessTokenParameter)
+	newRequest := func(method, contentType string, values url.Values) *http.Request {
+		r := httptest.NewRequest(method, "/?access_token=query", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", contentType)
+		return r
+	}
+
+	form := url.Values{AccessTokenParameter: {"value"}}
+	testExtract(t, extractor, newRequest(http.MethodPost, "application/x-www-form-urlencoded", form), "value", nil)
+	testExtract(t, extractor, newRequest(http.MethodPost, "application/x-www-form-urlencoded; charset=utf-8", form), "value", nil)
+	testExtract(t, extractor, newRequest(http.MethodPost, "application/json", form), "", ErrNoToken)
+	testExtract(t, extractor, newRequest(http.MethodGet, "application/x-www-form-urlencoded", form), "", ErrNoToken)
+	testExtract(t, extractor, newRequest(http.MethodPost, "application/x-www-form-urlencoded", url.Values{}), "", ErrNoToken)
+}
+
+func TestChainExtractors(t *testing.T) {
+	extractor := ChainExtractors(BearerExtractor(), CookieExtractor("token"), QueryExtractor(AccessTokenParameter))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	testExtract(t, extractor, r, "", ErrNoToken)
+
+	r.AddCookie(&http.Cookie{Name: "token", Value: "cookie"})
+	testExtract(t, extractor, r, "cookie", nil)
+
+	r.Header.Set("Authorization", "Bearer header")
+	testExtract(t, extractor, r, "", ErrAmbiguousToken)
+
+	r = httptest.NewRequest(http.MethodGet, "/?access_token=query", nil)
+	testExtract(t, extractor, r, "query", nil)
+
+	fail := errors.New("fail")
+	failing := ChainExtractors(ExtractorFunc(func(r *http.Request) ([]byte, error) {
+		return nil, fail
+	}), QueryExtractor(AccessTokenParameter))
+	testExtract(t, failing, r, "", fail)
+}
+
+func TestMiddleware_AmbiguousToken(t *testing.T) {
+	valid := signTestToken(t, BasicClaims{Subject: "user"})
+	m := NewMiddleware(NewParser(), NewValidator(),
+		WithExtractor(ChainExtractors(BearerExtractor(), QueryExtractor(AccessTokenParameter))))
+
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/?access_token="+valid, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	r.Header.Set("Authorization", "Bearer "+valid)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `Bearer error="invalid_request"`, w.Header().Get("WWW-Authenticate"))
+}
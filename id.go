@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// RandomIdSize is number of random bytes in identifiers returned by NewRandomId
+	RandomIdSize = 16
+
+	uuidLength = 36
+)
+
+var (
+	ErrMalformedId = errors.New("token id is malformed")
+)
+
+// IdGenerator returns new unique token identifier for "jti" claim
+type IdGenerator func() (string, error)
+
+// IdFormat reports whether token identifier is well-formed
+type IdFormat func(id string) bool
+
+// NewUUID returns random UUID version 4 in canonical form, e.g. "e62e7e19-98f6-40eb-93ef-833a33b75a22"
+func NewUUID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]), nil
+}
+
+// NewRandomId returns compact base64url encoded identifier of RandomIdSize random bytes
+func NewRandomId() (string, error) {
+	id := make([]byte, RandomIdSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return toBase64(id), nil
+}
+
+// IsUUID reports whether id is UUID of any version in canonical form
+func IsUUID(id string) bool {
+	if len(id) != uuidLength {
+		return false
+	}
+
+	for i := 0; i < uuidLength; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if id[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(id[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// IsRandomId reports whether id is base64url encoded value of at least RandomIdSize bytes
+func IsRandomId(id string) bool {
+	decoded, err := fromBase64([]byte(id))
+	return err == nil && len(decoded) >= RandomIdSize
+}
+
+// WithGeneratedId sets "jti" claim to generated identifier if it is empty
+func WithGeneratedId(generator IdGenerator) WriteOption {
+	return func(t *Token, _ alg.Signer) error {
+		if len(t.Claims.Id) > 0 {
+			return nil
+		}
+
+		id, err := generator()
+		if err != nil {
+			return err
+		}
+
+		t.Claims.Id = id
+		return nil
+	}
+}
+
+// RequireId rejects tokens without "jti". If formats are passed, "jti" must match at least one of them
+func RequireId(formats ...IdFormat) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		if len(t.Claims.Id) == 0 {
+			return fmt.Errorf("%w: jti", ErrMissingClaim)
+		}
+		if len(formats) == 0 {
+			return nil
+		}
+
+		for _, format := range formats {
+			if format(t.Claims.Id) {
+				return nil
+			}
+		}
+
+		return ErrMalformedId
+	})
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUUID(t *testing.T) {
+	first, err := NewUUID()
+	require.NoError(t, err)
+	second, err := NewUUID()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, IsUUID(first))
+	assert.Equal(t, byte('4'), first[14])
+	assert.Contains(t, "89ab", string(first[19]))
+}
+
+func TestNewRandomId(t *testing.T) {
+	first, err := NewRandomId()
+	require.NoError(t, err)
+	second, err := NewRandomId()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, first, 22)
+	assert.True(t, IsRandomId(first))
+	assert.False(t, IsUUID(first))
+}
+
+func TestIdFormats(t *testing.T) {
+	tests := []struct {
+		id     string
+		uuid   bool
+		random bool
+	}{
+		{"e62e7e19-98f6-40eb-93ef-833a33b75a22", true, true},
+		{"E62E7E19-98F6-40EB-93EF-833A33B75A22", true, true},
+		{"e62e7e19-98f6-40eb-93ef-833a33b75a2", false, true},
+		{"e62e7e19x98f6-40eb-93ef-833a33b75a22", false, true},
+		{"g62e7e19-98f6-40eb-93ef-833a33b75a22", false, true},
+		{"AAECAwQFBgcICQoLDA0ODw", false, true},
+		{"AAECAwQFBgcICQoLDA0O", false, false},
+		{"AAECAwQFBgcICQoLDA0ODw==", false, false},
+		{"not+base64/url+but+long+enough", false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			assert.Equal(t, test.uuid, IsUUID(test.id))
+			assert.Equal(t, test.random, IsRandomId(test.id))
+		})
+	}
+}
+
+func TestWithGeneratedId(t *testing.T) {
+	registerTestHmac(t)
+
+	s, err := NewToken(alg.HS256).WriteString(WithGeneratedId(NewUUID))
+	require.NoError(t, err)
+	token, err := Parse([]byte(s))
+	require.NoError(t, err)
+	assert.True(t, IsUUID(token.Claims.Id))
+
+	token.Claims.Id = "id"
+	s, err = token.WriteString(WithGeneratedId(NewUUID))
+	require.NoError(t, err)
+	token, err = Parse([]byte(s))
+	require.NoError(t, err)
+	assert.Equal(t, "id", token.Claims.Id)
+
+	_, err = NewToken(alg.HS256).WriteString(WithGeneratedId(func() (string, error) {
+		return "", errors.New("no entropy")
+	}))
+	assert.EqualError(t, err, "no entropy")
+}
+
+func TestRequireId(t *testing.T) {
+	token := NewToken(alg.HS256)
+
+	err := NewValidator(RequireId()).Validate(token)
+	assert.True(t, errors.Is(err, ErrMissingClaim))
+
+	token.Claims.Id = "e62e7e19-98f6-40eb-93ef-833a33b75a22"
+	assert.NoError(t, NewValidator(RequireId()).Validate(token))
+	assert.NoError(t, NewValidator(RequireId(IsUUID)).Validate(token))
+	assert.NoError(t, NewValidator(RequireId(IsRandomId, IsUUID)).Validate(token))
+
+	token.Claims.Id = "AAECAwQFBgcICQoLDA0ODw"
+	assert.NoError(t, NewValidator(RequireId(IsRandomId)).Validate(token))
+
+	err = NewValidator(RequireId(IsUUID)).Validate(token)
+	assert.True(t, errors.Is(err, ErrMalformedId))
+}
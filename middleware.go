@@ -0,0 +1,270 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ErrorCodeInvalidRequest is RFC 6750 error code of malformed requests
+	ErrorCodeInvalidRequest = "invalid_request"
+	// ErrorCodeInvalidToken is RFC 6750 error code of expired, revoked, malformed or otherwise invalid tokens
+	ErrorCodeInvalidToken = "invalid_token"
+	// ErrorCodeInsufficientScope is RFC 6750 error code of tokens without required privileges
+	ErrorCodeInsufficientScope = "insufficient_scope"
+
+	bearerScheme = "Bearer"
+)
+
+var (
+	ErrNoToken = errors.New("request has no token")
+)
+
+// Extractor extracts raw token from the request. It returns ErrNoToken if the request has no token
+type Extractor interface {
+	Extract(r *http.Request) ([]byte, error)
+}
+
+// Authorizer decides whether the valid token grants access to the request.
+// Returned InsufficientScopeError is reported with the scope required
+type Authorizer func(r *http.Request, t Token) error
+
+// InsufficientScopeError is returned when the token lacks privileges, Scope lists the required ones
+type InsufficientScopeError struct {
+	Scope []string
+}
+
+func (e InsufficientScopeError) Error() string {
+	if len(e.Scope) == 0 {
+		return "insufficient scope"
+	}
+
+	return fmt.Sprintf("insufficient scope, required: %s", strings.Join(e.Scope, " "))
+}
+
+// ErrorHandler writes response to request r failed with err which is not caused by the token
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// MiddlewareOption configures Middleware
+type MiddlewareOption func(m *Middleware)
+
+// WithRealm sets "realm" attribute of WWW-Authenticate challenge
+func WithRealm(realm string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.realm = realm
+	}
+}
+
+// WithExtractor replaces token extractor, BearerExtractor is used by default
+func WithExtractor(extractor Extractor) MiddlewareOption {
+	return func(m *Middleware) {
+		m.extractor = extractor
+	}
+}
+
+// WithAuthorizer adds authorization step performed after the token is validated
+func WithAuthorizer(authorizer Authorizer) MiddlewareOption {
+	return func(m *Middleware) {
+		m.authorizers = append(m.authorizers, authorizer)
+	}
+}
+
+// WithErrorDescription includes error messages into "error_description" attribute.
+// Disabled by default to not disclose validation details
+func WithErrorDescription() MiddlewareOption {
+	return func(m *Middleware) {
+		m.describeErrors = true
+	}
+}
+
+// WithErrorHandler replaces handler of validation errors which are not caused by the token (see IsTokenError),
+// e.g. failures of replay cache or revocation list backends. Such requests are answered with 500 status by default
+func WithErrorHandler(handler ErrorHandler) MiddlewareOption {
+	return func(m *Middleware) {
+		m.errorHandler = handler
+	}
+}
+
+// Middleware authenticates requests by JSON web tokens
+type Middleware struct {
+	parser         Parser
+	validator      Validator
+	extractor      Extractor
+	authorizers    []Authorizer
+	errorHandler   ErrorHandler
+	realm          string
+	describeErrors bool
+}
+
+// NewMiddleware returns Middleware verifying tokens with parser and checking them with validator
+func NewMiddleware(parser Parser, validator Validator, options ...MiddlewareOption) Middleware {
+	m := Middleware{
+		parser:       parser,
+		validator:    validator,
+		extractor:    BearerExtractor(),
+		errorHandler: internalServerError,
+	}
+	for _, option := range options {
+		option(&m)
+	}
+
+	return m
+}
+
+// Handler returns http.Handler which calls next only for requests with valid token.
+// The token is available in handlers by FromContext. Other requests are rejected
+// with 400, 401 or 403 status and RFC 6750 WWW-Authenticate challenge, failures of validation
+// backends are passed to the error handler
+func (m Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := m.Authenticate(r)
+		var backendErr *backendError
+		switch {
+		case errors.As(err, &backendErr):
+			m.errorHandler(w, r, backendErr.err)
+			return
+		case err != nil:
+			m.writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), token)))
+	})
+}
+
+// Authenticate extracts, verifies, validates and authorizes token of the request
+func (m Middleware) Authenticate(r *http.Request) (Token, error) {
+	data, err := m.extractor.Extract(r)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token, err := m.parser.Parse(data)
+	if err != nil {
+		return Token{}, &authError{code: ErrorCodeInvalidToken, err: err}
+	}
+	if err = m.validator.Validate(token); err != nil {
+		if !IsTokenError(err) {
+			return Token{}, &backendError{err: err}
+		}
+
+		return Token{}, &authError{code: ErrorCodeInvalidToken, err: err}
+	}
+
+	for _, authorize := range m.authorizers {
+		if err = authorize(r, token); err != nil {
+			code := ErrorCodeInsufficientScope
+			if errors.Is(err, ErrConfirmationMismatch) {
+				code = ErrorCodeInvalidToken
+			}
+
+			return Token{}, &authError{code: code, err: err}
+		}
+	}
+
+	return token, nil
+}
+
+func (m Middleware) writeError(w http.ResponseWriter, err error) {
+	status, code := http.StatusUnauthorized, ""
+
+	var authErr *authError
+	switch {
+	case errors.Is(err, ErrNoToken):
+	case errors.As(err, &authErr):
+		code = authErr.code
+		if code == ErrorCodeInsufficientScope {
+			status = http.StatusForbidden
+		}
+	default:
+		status, code = http.StatusBadRequest, ErrorCodeInvalidRequest
+	}
+
+	attributes := make([]string, 0, 4)
+	if len(m.realm) > 0 {
+		attributes = append(attributes, challengeAttribute("realm", m.realm))
+	}
+	if len(code) > 0 {
+		attributes = append(attributes, challengeAttribute("error", code))
+		if m.describeErrors {
+			attributes = append(attributes, challengeAttribute("error_description", err.Error()))
+		}
+	}
+
+	var scopeErr InsufficientScopeError
+	if errors.As(err, &scopeErr) && len(scopeErr.Scope) > 0 {
+		attributes = append(attributes, challengeAttribute("scope", strings.Join(scopeErr.Scope, " ")))
+	}
+
+	challenge := bearerScheme
+	if len(attributes) > 0 {
+		challenge += " " + strings.Join(attributes, ", ")
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// challengeAttribute formats auth-param, characters not allowed by RFC 6750 are replaced
+func challengeAttribute(name, value string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < 0x20 || r > 0x7e {
+			return '\''
+		}
+
+		return r
+	}, value)
+
+	return fmt.Sprintf(`%s="%s"`, name, cleaned)
+}
+
+type authError struct {
+	code string
+	err  error
+}
+
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+func (e *authError) Unwrap() error {
+	return e.err
+}
+
+// backendError is validation error not caused by the token, it must not be reported as invalid_token
+type backendError struct {
+	err error
+}
+
+func (e *backendError) Error() string {
+	return e.err.Error()
+}
+
+func (e *backendError) Unwrap() error {
+	return e.err
+}
+
+func internalServerError(w http.ResponseWriter, _ *http.Request, _ error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+type contextKey struct{}
+
+// NewContext returns copy of ctx carrying the token
+func NewContext(ctx context.Context, t Token) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns token stored by NewContext or Middleware
+func FromContext(ctx context.Context) (Token, bool) {
+	t, ok := ctx.Value(contextKey{}).(Token)
+	return t, ok
+}
+
+// FromRequest returns token of the request authenticated by Middleware
+func FromRequest(r *http.Request) (Token, bool) {
+	return FromContext(r.Context())
+}
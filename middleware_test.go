@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func registerTestHmac(t *testing.T) {
+	t.Helper()
+
+	hs256, err := alg.NewHmacSha(alg.HS256, testSecret)
+	require.NoError(t, err)
+	Register(alg.HS256, hs256, hs256)
+}
+
+func signTestToken(t *testing.T, claims BasicClaims) string {
+	t.Helper()
+	registerTestHmac(t)
+
+	token := NewToken(alg.HS256)
+	token.Claims.BasicClaims = claims
+
+	s, err := token.WriteString()
+	require.NoError(t, err)
+
+	return s
+}
+
+func serveTestRequest(m Middleware, authorization string) (*httptest.ResponseRecorder, *Token) {
+	var seen *Token
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := FromRequest(r); ok {
+			seen = &token
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	if len(authorization) > 0 {
+		r.Header.Set("Authorization", authorization)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w, seen
+}
+
+func TestMiddleware(t *testing.T) {
+	valid := signTestToken(t, BasicClaims{Subject: "user", ExpiresAt: NewPosixTime(time.Now().Add(time.Hour))})
+	expired := signTestToken(t, BasicClaims{Subject: "user", ExpiresAt: NewPosixTime(time.Now().Add(-time.Hour))})
+	m := NewMiddleware(NewParser(), NewValidator(), WithRealm("api"))
+
+	t.Run("valid", func(t *testing.T) {
+		w, token := serveTestRequest(m, "Bearer "+valid)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		require.NotNil(t, token)
+		assert.Equal(t, "user", token.Claims.Subject)
+
+		w, _ = serveTestRequest(m, "bearer "+valid)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+	t.Run("no token", func(t *testing.T) {
+		w, token := serveTestRequest(m, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+		assert.Nil(t, token)
+
+		w, _ = serveTestRequest(m, "Basic dXNlcjpwYXNz")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+	})
+	t.Run("malformed request", func(t *testing.T) {
+		w, _ := serveTestRequest(m, "Bearer ")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, `Bearer realm="api", error="invalid_request"`, w.Header().Get("WWW-Authenticate"))
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		w, _ := serveTestRequest(m, "Bearer "+expired)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="api", error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+
+		w, _ = serveTestRequest(m, "Bearer "+valid+"x")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		described := NewMiddleware(NewParser(), NewValidator(), WithErrorDescription())
+		w, _ = serveTestRequest(described, "Bearer "+expired)
+		assert.Equal(t, `Bearer error="invalid_token", error_description="token is expired"`, w.Header().Get("WWW-Authenticate"))
+	})
+	t.Run("backend failure", func(t *testing.T) {
+		failure := errors.New("backend is unavailable")
+		failing := NewValidator(WithCheck(func(t Token) error {
+			return failure
+		}))
+
+		w, _ := serveTestRequest(NewMiddleware(NewParser(), failing), "Bearer "+valid)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+
+		var handled error
+		handler := WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		w, _ = serveTestRequest(NewMiddleware(NewParser(), failing, handler), "Bearer "+valid)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, failure, handled)
+
+		r := httptest.NewRequest(http.MethodGet, "/resource", nil)
+		r.Header.Set("Authorization", "Bearer "+valid)
+		_, err := NewMiddleware(NewParser(), failing).Authenticate(r)
+		assert.True(t, errors.Is(err, failure))
+		assert.False(t, IsTokenError(err))
+	})
+	t.Run("insufficient scope", func(t *testing.T) {
+		forbidden := NewMiddleware(NewParser(), NewValidator(), WithAuthorizer(func(r *http.Request, t Token) error {
+			return InsufficientScopeError{Scope: []string{"admin", "write"}}
+		}))
+
+		w, _ := serveTestRequest(forbidden, "Bearer "+valid)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="admin write"`, w.Header().Get("WWW-Authenticate"))
+
+		denied := NewMiddleware(NewParser(), NewValidator(), WithAuthorizer(func(r *http.Request, t Token) error {
+			return errors.New(`not "allowed"`)
+		}), WithErrorDescription())
+		w, _ = serveTestRequest(denied, "Bearer "+valid)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", error_description="not 'allowed'"`, w.Header().Get("WWW-Authenticate"))
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, ok := FromRequest(r)
+	assert.False(t, ok)
+
+	token := NewToken(alg.HS256)
+	token.Claims.Subject = "user"
+
+	token, ok = FromContext(NewContext(r.Context(), token))
+	require.True(t, ok)
+	assert.Equal(t, "user", token.Claims.Subject)
+}
@@ -11,6 +11,14 @@ type Header struct {
 	Type        Type          `json:"typ"`
 	ContentType string        `json:"cty,omitempty"`
 	KeyId       string        `json:"kid,omitempty"`
+	// X509CertChain contains base64 (not base64url) DER encoded certificates, leaf first, optional
+	X509CertChain []string `json:"x5c,omitempty"`
+	// X509Thumbprint contains base64url SHA-1 thumbprint of the leaf certificate, optional
+	X509Thumbprint string `json:"x5t,omitempty"`
+	// X509ThumbprintS256 contains base64url SHA-256 thumbprint of the leaf certificate, optional
+	X509ThumbprintS256 string `json:"x5t#S256,omitempty"`
+	// JWK contains public key the token is signed with, used by DPoP proofs, optional
+	JWK *alg.JWK `json:"jwk,omitempty"`
 }
 
 type BasicClaims struct {
@@ -35,50 +43,25 @@ type Claims struct {
 	BasicClaims
 }
 
+// MarshalJSON encodes claims from raw keeping their order and values byte-for-byte,
+// registered claims changed in BasicClaims replace raw values or are appended in alphabetical order
 func (c Claims) MarshalJSON() ([]byte, error) {
-	temp := make(map[string]interface{})
-	if len(c.raw) > 0 {
-		err := json.Unmarshal(c.raw, &temp)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if c.IssuedAt != nil {
-		temp["iat"] = c.IssuedAt
-	}
-	if c.ExpiresAt != nil {
-		temp["exp"] = c.ExpiresAt
-	}
-	if c.NotBefore != nil {
-		temp["nbf"] = c.NotBefore
-	}
-
-	if len(c.Id) > 0 {
-		temp["jti"] = c.Id
-	}
-	if len(c.Issuer) > 0 {
-		temp["iss"] = c.Issuer
-	}
-	if len(c.Subject) > 0 {
-		temp["sub"] = c.Subject
-	}
-	if len(c.Audience) > 0 {
-		temp["aud"] = c.Audience
+	fields, err := c.fields()
+	if err != nil {
+		return nil, err
 	}
 
-	return json.Marshal(temp)
+	return fields.MarshalJSON()
 }
 
 func (c *Claims) UnmarshalJSON(bytes []byte) error {
-	c.raw = bytes
-
 	temp := BasicClaims{}
 	err := json.Unmarshal(bytes, &temp)
 	if err != nil {
 		return err
 	}
 
+	c.raw = append([]byte(nil), bytes...)
 	c.BasicClaims = temp
 	return nil
 }
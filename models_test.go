@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -85,6 +87,64 @@ func TestClaims_JSON(t *testing.T) {
 	})
 }
 
+func TestClaims_Precision(t *testing.T) {
+	data := `{"z":1,"account_id":9007199254740993,"ratio":1.10,"sub":"user","nested":{"b":1,"a":12345678901234567890},"iat":1654992000}`
+
+	t.Run("untouched", func(t *testing.T) {
+		claims := Claims{}
+		require.NoError(t, json.Unmarshal([]byte(data), &claims))
+
+		b, err := json.Marshal(claims)
+		require.NoError(t, err)
+		assert.Equal(t, data, string(b))
+
+		id, err := claims.GetInt64("account_id")
+		require.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), id)
+	})
+	t.Run("registered changed", func(t *testing.T) {
+		claims := Claims{}
+		require.NoError(t, json.Unmarshal([]byte(data), &claims))
+		claims.Subject = "admin"
+		claims.Issuer = "issuer"
+
+		b, err := json.Marshal(claims)
+		require.NoError(t, err)
+		assert.Equal(t, `{"z":1,"account_id":9007199254740993,"ratio":1.10,"sub":"admin","nested":{"b":1,"a":12345678901234567890},"iat":1654992000,"iss":"issuer"}`, string(b))
+	})
+	t.Run("put", func(t *testing.T) {
+		claims := Claims{}
+		require.NoError(t, json.Unmarshal([]byte(data), &claims))
+		require.NoError(t, claims.Put("nested.c", true))
+		require.NoError(t, claims.Delete("z"))
+
+		b, err := json.Marshal(claims)
+		require.NoError(t, err)
+		assert.Equal(t, `{"account_id":9007199254740993,"ratio":1.10,"sub":"user","nested":{"b":1,"a":12345678901234567890,"c":true},"iat":1654992000}`, string(b))
+	})
+	t.Run("token", func(t *testing.T) {
+		registerTestHmac(t)
+
+		token := NewToken(alg.HS256)
+		require.NoError(t, json.Unmarshal([]byte(data), &token.Claims))
+		s, err := token.WriteString()
+		require.NoError(t, err)
+
+		parsed, err := Parse([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, data, string(parsed.Claims.raw))
+	})
+	t.Run("null", func(t *testing.T) {
+		claims := Claims{}
+		require.NoError(t, json.Unmarshal([]byte(`null`), &claims))
+		claims.Subject = "user"
+
+		b, err := json.Marshal(claims)
+		require.NoError(t, err)
+		assert.Equal(t, `{"sub":"user"}`, string(b))
+	})
+}
+
 func TestClaims_Set(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		claims := Claims{}
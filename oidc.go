@@ -0,0 +1,183 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// AuthorizedPartyClaim is OpenID Connect "azp" claim, client id the token was issued to
+	AuthorizedPartyClaim = "azp"
+	// NonceClaim is OpenID Connect "nonce" claim, value passed in authentication request
+	NonceClaim = "nonce"
+	// AuthTimeClaim is OpenID Connect "auth_time" claim, time of end-user authentication
+	AuthTimeClaim = "auth_time"
+	// AcrClaim is OpenID Connect "acr" claim, authentication context class reference
+	AcrClaim = "acr"
+	// AmrClaim is OpenID Connect "amr" claim, authentication methods references
+	AmrClaim = "amr"
+	// AccessTokenHashClaim is OpenID Connect "at_hash" claim
+	AccessTokenHashClaim = "at_hash"
+	// CodeHashClaim is OpenID Connect "c_hash" claim
+	CodeHashClaim = "c_hash"
+)
+
+var (
+	ErrAuthorizedPartyMismatch = errors.New("token is issued to another client")
+	ErrNonceMismatch           = errors.New("token nonce mismatch")
+	ErrAuthenticationExpired   = errors.New("end-user authentication is too old")
+	ErrAcrMismatch             = errors.New("authentication context class is not accepted")
+	ErrAmrMismatch             = errors.New("required authentication methods were not used")
+	ErrTokenHashMismatch       = errors.New("token hash mismatch")
+)
+
+// ValidateIdToken configures Validator to check OpenID Connect ID token of the client issued by issuer:
+// "iss", "sub", "aud", "exp" and "iat" are required, "aud" must contain clientId.
+// If the token has several audiences, "azp" is required, if present it must be equal to clientId.
+// Security Event Tokens are rejected
+func ValidateIdToken(issuer, clientId string) ValidatorOption {
+	return func(v *Validator) {
+		WithIssuer(issuer)(v)
+		WithAudience(clientId)(v)
+		RequireExpiration()(v)
+		RejectSecurityEvents()(v)
+		withRule(func(_ Validator, t Token) error {
+			return checkIdToken(t, clientId)
+		})(v)
+	}
+}
+
+// WithNonce requires "nonce" claim equal to the value sent in authentication request
+func WithNonce(nonce string) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		value, err := t.Claims.GetString(NonceClaim)
+		if err != nil {
+			return err
+		}
+		if !isConstTimeEqualsString(value, nonce) {
+			return ErrNonceMismatch
+		}
+
+		return nil
+	})
+}
+
+// WithMaxAge requires "auth_time" claim not older than maxAge, like "max_age" authentication request parameter
+func WithMaxAge(maxAge time.Duration) ValidatorOption {
+	return withRule(func(v Validator, t Token) error {
+		authTime, err := t.Claims.GetTime(AuthTimeClaim)
+		if err != nil {
+			return err
+		}
+		if v.Now().Add(-v.leeway).After(authTime.Add(maxAge)) {
+			return ErrAuthenticationExpired
+		}
+
+		return nil
+	})
+}
+
+// RequireAcr requires "acr" claim equal to one of values
+func RequireAcr(values ...string) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		acr, err := t.Claims.GetString(AcrClaim)
+		if err != nil {
+			return err
+		}
+		if !containsConstTime(values, acr) {
+			return fmt.Errorf("%w: \"%s\"", ErrAcrMismatch, acr)
+		}
+
+		return nil
+	})
+}
+
+// RequireAmr requires "amr" claim containing all of methods, e.g. "pwd" and "otp"
+func RequireAmr(methods ...string) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		amr, err := t.Claims.GetStrings(AmrClaim)
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			if !containsConstTime(amr, method) {
+				return fmt.Errorf("%w: %s", ErrAmrMismatch, method)
+			}
+		}
+
+		return nil
+	})
+}
+
+// VerifyAccessTokenHash requires "at_hash" claim matching accessToken issued with the ID token
+func VerifyAccessTokenHash(accessToken string) ValidatorOption {
+	return verifyTokenHash(AccessTokenHashClaim, accessToken)
+}
+
+// VerifyCodeHash requires "c_hash" claim matching authorization code issued with the ID token
+func VerifyCodeHash(code string) ValidatorOption {
+	return verifyTokenHash(CodeHashClaim, code)
+}
+
+// TokenHash returns "at_hash" or "c_hash" value: base64url encoded left-most half
+// of the value hash, the hash function is the one used by algorithm a
+func TokenHash(a alg.Algorithm, value string) (string, error) {
+	hash, err := a.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	h := hash.New()
+	h.Write([]byte(value))
+	sum := h.Sum(nil)
+
+	return toBase64(sum[:len(sum)/2]), nil
+}
+
+func verifyTokenHash(claim, value string) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		actual, err := t.Claims.GetString(claim)
+		if err != nil {
+			return err
+		}
+
+		expected, err := TokenHash(t.Header.Algorithm, value)
+		if err != nil {
+			return err
+		}
+		if !isConstTimeEqualsString(actual, expected) {
+			return fmt.Errorf("%w: %s", ErrTokenHashMismatch, claim)
+		}
+
+		return nil
+	})
+}
+
+func checkIdToken(t Token, clientId string) error {
+	if len(t.Claims.Subject) == 0 {
+		return fmt.Errorf("%w: sub", ErrMissingClaim)
+	}
+	if t.Claims.IssuedAt == nil {
+		return fmt.Errorf("%w: iat", ErrMissingClaim)
+	}
+
+	azp, err := t.Claims.GetString(AuthorizedPartyClaim)
+	switch {
+	case errors.Is(err, ErrMissingClaim):
+		if len(t.Claims.Audience) > 1 {
+			return fmt.Errorf("%w: azp is required for multiple audiences", ErrMissingClaim)
+		}
+
+		return nil
+	case err != nil:
+		return err
+	case !isConstTimeEqualsString(azp, clientId):
+		return fmt.Errorf("%w: \"%s\"", ErrAuthorizedPartyMismatch, azp)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdToken(t *testing.T, now time.Time, claims map[string]interface{}) Token {
+	t.Helper()
+
+	token := NewToken(alg.RS256)
+	token.Claims.Issuer = "https://accounts.example.com"
+	token.Claims.Subject = "user"
+	token.Claims.Audience = Audience{"client"}
+	token.Claims.IssuedAt = NewPosixTime(now)
+	token.Claims.ExpiresAt = NewPosixTime(now.Add(time.Hour))
+	for key, value := range claims {
+		require.NoError(t, token.Claims.Put(key, value))
+	}
+
+	return token
+}
+
+func TestTokenHash(t *testing.T) {
+	// OpenID Connect Core 1.0, A.4
+	hash, err := TokenHash(alg.RS256, "Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk")
+	require.NoError(t, err)
+	assert.Equal(t, "LDktKdoQak3Pk0cnXxCltA", hash)
+
+	hash, err = TokenHash(alg.ES384, "code")
+	require.NoError(t, err)
+	assert.Len(t, hash, 32)
+
+	hash, err = TokenHash(alg.EdDSA, "code")
+	require.NoError(t, err)
+	assert.Len(t, hash, 43)
+
+	_, err = TokenHash(alg.None, "code")
+	assert.Error(t, err)
+}
+
+func TestValidateIdToken(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	validator := NewValidator(WithClock(clock), ValidateIdToken("https://accounts.example.com", "client"))
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, validator.Validate(newIdToken(t, now, nil)))
+
+		token := newIdToken(t, now, map[string]interface{}{"azp": "client"})
+		token.Claims.Audience = Audience{"client", "api"}
+		assert.NoError(t, validator.Validate(token))
+	})
+	t.Run("required claims", func(t *testing.T) {
+		for _, claim := range []string{"iss", "sub", "aud", "exp", "iat"} {
+			token := newIdToken(t, now, nil)
+			require.NoError(t, token.Claims.Delete(claim))
+			assert.Error(t, validator.Validate(token), claim)
+		}
+
+		token := newIdToken(t, now, nil)
+		token.Claims.Subject = ""
+		assert.True(t, errors.Is(validator.Validate(token), ErrMissingClaim))
+	})
+	t.Run("audience", func(t *testing.T) {
+		token := newIdToken(t, now, nil)
+		token.Claims.Audience = Audience{"other"}
+		assert.True(t, errors.Is(validator.Validate(token), ErrAudienceMismatch))
+	})
+	t.Run("azp", func(t *testing.T) {
+		token := newIdToken(t, now, nil)
+		token.Claims.Audience = Audience{"client", "api"}
+		assert.True(t, errors.Is(validator.Validate(token), ErrMissingClaim))
+
+		token = newIdToken(t, now, map[string]interface{}{"azp": "api"})
+		assert.True(t, errors.Is(validator.Validate(token), ErrAuthorizedPartyMismatch))
+
+		token = newIdToken(t, now, map[string]interface{}{"azp": 1})
+		assert.True(t, errors.Is(validator.Validate(token), ErrInvalidClaim))
+	})
+}
+
+func TestIdTokenOptions(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	code := "Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk"
+	accessToken := "jHkWEdUXMU1BwAsC4vtUsZwnNdsb"
+	atHash, err := TokenHash(alg.RS256, accessToken)
+	require.NoError(t, err)
+
+	token := newIdToken(t, now, map[string]interface{}{
+		"nonce":     "n-0S6_WzA2Mj",
+		"auth_time": now.Add(-10 * time.Minute).Unix(),
+		"acr":       "urn:mace:incommon:iap:silver",
+		"amr":       []string{"pwd", "otp"},
+		"at_hash":   atHash,
+		"c_hash":    "LDktKdoQak3Pk0cnXxCltA",
+	})
+
+	tests := []struct {
+		name   string
+		option ValidatorOption
+		err    error
+	}{
+		{"nonce", WithNonce("n-0S6_WzA2Mj"), nil},
+		{"nonce mismatch", WithNonce("other"), ErrNonceMismatch},
+		{"max age", WithMaxAge(15 * time.Minute), nil},
+		{"max age exceeded", WithMaxAge(5 * time.Minute), ErrAuthenticationExpired},
+		{"acr", RequireAcr("urn:mace:incommon:iap:bronze", "urn:mace:incommon:iap:silver"), nil},
+		{"acr mismatch", RequireAcr("urn:mace:incommon:iap:gold"), ErrAcrMismatch},
+		{"amr", RequireAmr("otp"), nil},
+		{"amr mismatch", RequireAmr("pwd", "hwk"), ErrAmrMismatch},
+		{"at_hash", VerifyAccessTokenHash(accessToken), nil},
+		{"at_hash mismatch", VerifyAccessTokenHash("other"), ErrTokenHashMismatch},
+		{"c_hash", VerifyCodeHash(code), nil},
+		{"c_hash mismatch", VerifyCodeHash("other"), ErrTokenHashMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewValidator(WithClock(clock), ValidateIdToken("https://accounts.example.com", "client"), test.option).
+				Validate(token)
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.err), err)
+		})
+	}
+
+	t.Run("max age leeway", func(t *testing.T) {
+		validator := NewValidator(WithClock(clock), WithLeeway(5*time.Minute), WithMaxAge(5*time.Minute))
+		assert.NoError(t, validator.Validate(token))
+	})
+	t.Run("missing claims", func(t *testing.T) {
+		token := newIdToken(t, now, nil)
+		options := []ValidatorOption{
+			WithNonce("n"), WithMaxAge(time.Minute), RequireAcr("acr"), RequireAmr("pwd"),
+			VerifyAccessTokenHash(accessToken), VerifyCodeHash(code),
+		}
+		for _, option := range options {
+			err := NewValidator(WithClock(clock), option).Validate(token)
+			assert.True(t, errors.Is(err, ErrMissingClaim), err)
+		}
+	})
+	t.Run("signed token", func(t *testing.T) {
+		registerTestHmac(t)
+
+		token := newIdToken(t, now, map[string]interface{}{"nonce": "n"})
+		token.Header.Algorithm = alg.HS256
+		hash, err := TokenHash(alg.HS256, accessToken)
+		require.NoError(t, err)
+		require.NoError(t, token.Claims.Put(AccessTokenHashClaim, hash))
+
+		s, err := token.WriteString()
+		require.NoError(t, err)
+		parsed, err := Parse([]byte(s))
+		require.NoError(t, err)
+
+		assert.NoError(t, NewValidator(
+			WithClock(clock),
+			ValidateIdToken("https://accounts.example.com", "client"),
+			WithNonce("n"),
+			VerifyAccessTokenHash(accessToken),
+		).Validate(parsed))
+	})
+}
@@ -20,15 +20,162 @@ func Register(algorithm alg.Algorithm, verifier alg.Verifier, signer alg.Signer)
 	signers[algorithm] = signer
 }
 
+// VerifierResolver selects Verifier for the token using its header.
+// It allows to verify tokens with keys which are not known in advance, e.g. taken from "x5c"
+type VerifierResolver interface {
+	ResolveVerifier(header Header) (alg.Verifier, error)
+}
+
+// ParseOption configures Parser
+type ParseOption func(p *Parser)
+
+// WithTypes replaces the list of accepted "typ" values. Types are compared case-insensitively,
+// "application/" prefix and media type parameters are ignored.
+// Tokens without "typ" are still accepted unless RequireType is used. Empty list disables "typ" checks like WithAnyType
+func WithTypes(types ...Type) ParseOption {
+	return func(p *Parser) {
+		if len(types) == 0 {
+			p.types = nil
+			return
+		}
+
+		p.types = normalizeTypes(types)
+	}
+}
+
+// WithAnyType disables "typ" checks
+func WithAnyType() ParseOption {
+	return func(p *Parser) {
+		p.types = nil
+	}
+}
+
+// RequireType makes "typ" mandatory and accepts only specified type.
+// Use it to prevent one kind of token (e.g. ID token) from being accepted as another (e.g. access token)
+func RequireType(t Type) ParseOption {
+	return func(p *Parser) {
+		p.types = normalizeTypes([]Type{t})
+		p.typeRequired = true
+	}
+}
+
+// WithVerifierResolver makes Parser take verifiers from resolver instead of registered ones
+func WithVerifierResolver(resolver VerifierResolver) ParseOption {
+	return func(p *Parser) {
+		p.resolver = resolver
+	}
+}
+
+// Parser parses and verifies tokens with configured options
+type Parser struct {
+	types        []Type
+	typeRequired bool
+	resolver     VerifierResolver
+}
+
+// NewParser returns Parser with applied options.
+// By default, tokens with "typ" JWT (or without "typ") are accepted
+func NewParser(options ...ParseOption) Parser {
+	p := Parser{
+		types: []Type{JsonWebTokenType.Normalize()},
+	}
+	for _, option := range options {
+		option(&p)
+	}
+
+	return p
+}
+
 // Parse returns Token parsed from byte array data or error if some troubles occurred
-func Parse(data []byte) (Token, error) {
+func Parse(data []byte, options ...ParseOption) (Token, error) {
+	return NewParser(options...).Parse(data)
+}
+
+// Parse returns Token parsed from byte array data or error if some troubles occurred
+func (p Parser) Parse(data []byte) (Token, error) {
+	header, parts, err := p.verify(data)
+	if err != nil {
+		return Token{}, err
+	}
+
+	claims := Claims{}
+	if err = json.Unmarshal(parts.claims, &claims); err != nil {
+		return Token{}, err
+	}
+
+	return Token{
+		Header:    header,
+		Claims:    claims,
+		signature: parts.signature,
+	}, nil
+}
+
+// verify splits the token, checks its type and signature and returns decoded header
+func (p Parser) verify(data []byte) (Header, tokenParts, error) {
+	parts, err := splitToken(data)
+	if err != nil {
+		return Header{}, tokenParts{}, err
+	}
+
+	header := Header{}
+	if err = json.Unmarshal(parts.header, &header); err != nil {
+		return Header{}, tokenParts{}, err
+	}
+	if err = p.checkType(header.Type); err != nil {
+		return Header{}, tokenParts{}, err
+	}
+
+	verifier, err := p.verifier(header)
+	if err != nil {
+		return Header{}, tokenParts{}, err
+	}
+
+	ok, err := verifier.Verify(parts.payload, parts.signature)
+	if err != nil {
+		return Header{}, tokenParts{}, err
+	}
+	if !ok {
+		return Header{}, tokenParts{}, ErrIncorrectSignature
+	}
+
+	return header, parts, nil
+}
+
+// Decode returns Token from byte array data WITHOUT signature verification and "typ" checks.
+// It is intended for debugging and inspecting tokens, decoded claims must not be trusted
+func Decode(data []byte) (Token, error) {
+	parts, err := splitToken(data)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := Token{signature: parts.signature}
+	if err = json.Unmarshal(parts.header, &token.Header); err != nil {
+		return Token{}, err
+	}
+	if err = json.Unmarshal(parts.claims, &token.Claims); err != nil {
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
+type tokenParts struct {
+	// payload is signed part of the token: base64 header and claims joined with dot
+	payload   []byte
+	header    []byte
+	claims    []byte
+	signature []byte
+}
+
+func splitToken(data []byte) (tokenParts, error) {
 	if len(data) == 0 {
-		return Token{}, ErrNoData
+		return tokenParts{}, ErrNoData
 	}
 
 	firstDot := bytes.Index(data, dotBytes)
 	if firstDot == -1 {
-		return Token{}, ErrIncorrectFormat
+		return tokenParts{}, ErrIncorrectFormat
 	}
 
 	secondDot := bytes.Index(data[firstDot+1:], dotBytes)
@@ -41,12 +188,12 @@ func Parse(data []byte) (Token, error) {
 	payloadBytes := data[:secondDot]
 	headerBytes, err := fromBase64(payloadBytes[:firstDot])
 	if err != nil {
-		return Token{}, fmt.Errorf("bad header: %v", err)
+		return tokenParts{}, fmt.Errorf("bad header: %v", err)
 	}
 
 	claimsBytes, err := fromBase64(payloadBytes[firstDot+1:])
 	if err != nil {
-		return Token{}, fmt.Errorf("bad claims: %v", err)
+		return tokenParts{}, fmt.Errorf("bad claims: %v", err)
 	}
 
 	var signatureBytes []byte
@@ -54,39 +201,58 @@ func Parse(data []byte) (Token, error) {
 		d := data[secondDot+1:]
 		signatureBytes, err = fromBase64(d)
 		if err != nil {
-			return Token{}, fmt.Errorf("bad signature: %v", err)
+			return tokenParts{}, fmt.Errorf("bad signature: %v", err)
 		}
 	}
 
-	header := Header{}
-	if err = json.Unmarshal(headerBytes, &header); err != nil {
-		return Token{}, err
-	}
-	if header.Type != JsonWebTokenType {
-		return Token{}, fmt.Errorf("token type \"%s\" not supported", header.Type)
+	return tokenParts{
+		payload:   payloadBytes,
+		header:    headerBytes,
+		claims:    claimsBytes,
+		signature: signatureBytes,
+	}, nil
+}
+
+func (p Parser) verifier(header Header) (alg.Verifier, error) {
+	if p.resolver != nil {
+		return p.resolver.ResolveVerifier(header)
 	}
 
 	verifier, ok := verifiers[header.Algorithm]
 	if !ok {
-		return Token{}, fmt.Errorf("unknown algorithm \"%s\"", header.Algorithm)
+		return nil, fmt.Errorf("unknown algorithm \"%s\"", header.Algorithm)
 	}
 
-	ok, err = verifier.Verify(payloadBytes, signatureBytes)
-	if err != nil {
-		return Token{}, err
+	return verifier, nil
+}
+
+func (p Parser) checkType(t Type) error {
+	if len(t) == 0 {
+		if p.typeRequired {
+			return fmt.Errorf("%w: typ is missing", ErrUnsupportedType)
+		}
+
+		return nil
 	}
-	if !ok {
-		return Token{}, ErrIncorrectSignature
+	if p.types == nil {
+		return nil
 	}
 
-	claims := Claims{}
-	if err = json.Unmarshal(claimsBytes, &claims); err != nil {
-		return Token{}, err
+	normalized := t.Normalize()
+	for _, accepted := range p.types {
+		if normalized == accepted {
+			return nil
+		}
 	}
 
-	return Token{
-		Header:    header,
-		Claims:    claims,
-		signature: signatureBytes,
-	}, nil
+	return fmt.Errorf("%w: \"%s\"", ErrUnsupportedType, t)
+}
+
+func normalizeTypes(types []Type) []Type {
+	normalized := make([]Type, len(types))
+	for i, t := range types {
+		normalized[i] = t.Normalize()
+	}
+
+	return normalized
 }
@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// RefreshTokenType is "typ" of refresh tokens issued by Refresher
+	RefreshTokenType Type = "refresh+jwt"
+	// RefreshFamilyClaim contains identifier of refresh token family, all rotated tokens share it
+	RefreshFamilyClaim = "fam"
+)
+
+var (
+	ErrRefreshTokenReused   = errors.New("refresh token is already rotated, token family is revoked")
+	ErrRefreshFamilyRevoked = errors.New("refresh token family is revoked or expired")
+)
+
+// RefreshStore tracks the only valid refresh token id of every family.
+// Implementations backed by Redis, SQL or other storages must perform Rotate atomically
+type RefreshStore interface {
+	// CreateFamily starts family with token id, the record may be dropped after expiresAt
+	CreateFamily(family, id string, expiresAt time.Time) error
+	// Rotate replaces current token id of the family with next and extends family to expiresAt.
+	// If id is not current, the family is revoked and ErrRefreshTokenReused is returned.
+	// Unknown or revoked families are reported with ErrRefreshFamilyRevoked
+	Rotate(family, id, next string, expiresAt time.Time) error
+	// RevokeFamily revokes all tokens of the family
+	RevokeFamily(family string) error
+}
+
+// RefresherOption configures Refresher
+type RefresherOption func(r *Refresher)
+
+// WithRefreshParser replaces parser of refresh tokens, by default only RefreshTokenType tokens
+// verified by registered verifiers are accepted
+func WithRefreshParser(parser Parser) RefresherOption {
+	return func(r *Refresher) {
+		r.parser = parser
+	}
+}
+
+// WithRefreshValidator replaces validator of refresh tokens, by default "exp" is required
+func WithRefreshValidator(validator Validator) RefresherOption {
+	return func(r *Refresher) {
+		r.validator = validator
+	}
+}
+
+// Refresher issues refresh tokens and rotates them on every use.
+// Presenting already rotated token revokes the whole family, so stolen tokens become useless
+// as soon as either the thief or the legitimate client uses the family again
+type Refresher struct {
+	template  Template
+	store     RefreshStore
+	parser    Parser
+	validator Validator
+}
+
+// NewRefresher returns Refresher issuing tokens from template, it should have ValidFor set.
+// "typ" of issued tokens is RefreshTokenType
+func NewRefresher(template Template, store RefreshStore, options ...RefresherOption) Refresher {
+	template.typ = RefreshTokenType
+	r := Refresher{
+		template:  template,
+		store:     store,
+		parser:    NewParser(RequireType(RefreshTokenType)),
+		validator: NewValidator(RequireExpiration()),
+	}
+	for _, option := range options {
+		option(&r)
+	}
+
+	return r
+}
+
+// Issue returns refresh token of new family for subject, e.g. after user login
+func (r Refresher) Issue(subject string) (string, error) {
+	family, err := NewRandomId()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := r.build(subject, family)
+	if err != nil {
+		return "", err
+	}
+
+	// the family is created only for tokens which were signed and can be handed out
+	s, err := token.WriteString()
+	if err != nil {
+		return "", err
+	}
+	if err = r.store.CreateFamily(family, token.Claims.Id, token.Claims.ExpiresAt.Time); err != nil {
+		return "", err
+	}
+
+	return s, nil
+}
+
+// Rotate verifies refresh token data and returns it with new refresh token of the same family,
+// the presented token becomes invalid
+func (r Refresher) Rotate(data []byte) (Token, string, error) {
+	token, family, err := r.parse(data)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	next, err := r.build(token.Claims.Subject, family)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	// sign before rotation, otherwise a signing failure would leave the store expecting a token
+	// the client never received, and the retry with the current token would revoke the family
+	s, err := next.WriteString()
+	if err != nil {
+		return Token{}, "", err
+	}
+	if err = r.store.Rotate(family, token.Claims.Id, next.Claims.Id, next.Claims.ExpiresAt.Time); err != nil {
+		return Token{}, "", err
+	}
+
+	return token, s, nil
+}
+
+// Revoke revokes family of refresh token data, e.g. on logout
+func (r Refresher) Revoke(data []byte) error {
+	_, family, err := r.parse(data)
+	if err != nil {
+		return err
+	}
+
+	return r.store.RevokeFamily(family)
+}
+
+func (r Refresher) parse(data []byte) (Token, string, error) {
+	token, err := r.parser.Parse(data)
+	if err != nil {
+		return Token{}, "", err
+	}
+	if err = r.validator.Validate(token); err != nil {
+		return Token{}, "", err
+	}
+	if len(token.Claims.Id) == 0 || token.Claims.ExpiresAt == nil {
+		return Token{}, "", fmt.Errorf("%w: jti and exp", ErrMissingClaim)
+	}
+
+	family, err := token.Claims.GetString(RefreshFamilyClaim)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	return token, family, nil
+}
+
+func (r Refresher) build(subject, family string) (Token, error) {
+	token, err := r.template.Build().
+		Subject(subject).
+		Claim(RefreshFamilyClaim, family).
+		Token()
+	if err != nil {
+		return Token{}, err
+	}
+	if len(token.Claims.Id) == 0 || token.Claims.ExpiresAt == nil {
+		return Token{}, fmt.Errorf("%w: template must generate jti and exp", ErrMissingClaim)
+	}
+
+	return token, nil
+}
+
+// RefreshStoreOption configures MemoryRefreshStore
+type RefreshStoreOption func(s *MemoryRefreshStore)
+
+// WithRefreshStoreClock replaces time source used to drop expired families, time.Now is used by default
+func WithRefreshStoreClock(clock func() time.Time) RefreshStoreOption {
+	return func(s *MemoryRefreshStore) {
+		s.clock = clock
+	}
+}
+
+// MemoryRefreshStore is RefreshStore kept in memory, expired families are dropped on creation of new ones
+type MemoryRefreshStore struct {
+	mutex    sync.Mutex
+	clock    func() time.Time
+	families map[string]*refreshFamily
+}
+
+type refreshFamily struct {
+	current   string
+	expiresAt time.Time
+	revoked   bool
+}
+
+// NewMemoryRefreshStore returns empty MemoryRefreshStore
+func NewMemoryRefreshStore(options ...RefreshStoreOption) *MemoryRefreshStore {
+	s := &MemoryRefreshStore{
+		clock:    time.Now,
+		families: make(map[string]*refreshFamily),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+func (s *MemoryRefreshStore) CreateFamily(family, id string, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.clock()
+	for name, f := range s.families {
+		if f.expiresAt.Before(now) {
+			delete(s.families, name)
+		}
+	}
+
+	if _, ok := s.families[family]; ok {
+		return fmt.Errorf("refresh token family %s already exists", family)
+	}
+
+	s.families[family] = &refreshFamily{current: id, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryRefreshStore) Rotate(family, id, next string, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, ok := s.families[family]
+	if !ok || f.revoked || f.expiresAt.Before(s.clock()) {
+		return ErrRefreshFamilyRevoked
+	}
+	if !isConstTimeEqualsString(f.current, id) {
+		f.revoked = true
+		return ErrRefreshTokenReused
+	}
+
+	f.current = next
+	f.expiresAt = expiresAt
+	return nil
+}
+
+func (s *MemoryRefreshStore) RevokeFamily(family string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if f, ok := s.families[family]; ok {
+		f.revoked = true
+	}
+
+	return nil
+}
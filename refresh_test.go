@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRefresher(t *testing.T, clock *testClock) (Refresher, *MemoryRefreshStore) {
+	t.Helper()
+	registerTestHmac(t)
+
+	store := NewMemoryRefreshStore(WithRefreshStoreClock(clock.Now))
+	template := NewTemplate(alg.HS256, IssuedBy("auth"), ValidFor(24*time.Hour), WithTemplateClock(clock.Now))
+	refresher := NewRefresher(template, store, WithRefreshValidator(NewValidator(
+		WithClock(clock.Now),
+		WithIssuer("auth"),
+		RequireExpiration(),
+	)))
+
+	return refresher, store
+}
+
+func TestRefresher(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	refresher, _ := newTestRefresher(t, clock)
+
+	first, err := refresher.Issue("user")
+	require.NoError(t, err)
+
+	t.Run("issued", func(t *testing.T) {
+		token, err := Parse([]byte(first), WithTypes(RefreshTokenType))
+		require.NoError(t, err)
+		assert.Equal(t, RefreshTokenType, token.Header.Type)
+		assert.Equal(t, "user", token.Claims.Subject)
+		assert.Equal(t, "auth", token.Claims.Issuer)
+		assert.True(t, token.Claims.Has(RefreshFamilyClaim))
+
+		_, err = Parse([]byte(first))
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+	})
+
+	clock.now = clock.now.Add(time.Hour)
+	old, second, err := refresher.Rotate([]byte(first))
+	require.NoError(t, err)
+	assert.Equal(t, "user", old.Claims.Subject)
+
+	t.Run("same family", func(t *testing.T) {
+		firstToken, err := Decode([]byte(first))
+		require.NoError(t, err)
+		secondToken, err := Decode([]byte(second))
+		require.NoError(t, err)
+
+		firstFamily, err := firstToken.Claims.GetString(RefreshFamilyClaim)
+		require.NoError(t, err)
+		secondFamily, err := secondToken.Claims.GetString(RefreshFamilyClaim)
+		require.NoError(t, err)
+
+		assert.Equal(t, firstFamily, secondFamily)
+		assert.NotEqual(t, firstToken.Claims.Id, secondToken.Claims.Id)
+		assert.Equal(t, clock.now.Add(24*time.Hour).Unix(), secondToken.Claims.ExpiresAt.Unix())
+	})
+	t.Run("reuse revokes family", func(t *testing.T) {
+		_, _, err := refresher.Rotate([]byte(first))
+		assert.True(t, errors.Is(err, ErrRefreshTokenReused))
+
+		_, _, err = refresher.Rotate([]byte(second))
+		assert.True(t, errors.Is(err, ErrRefreshFamilyRevoked))
+	})
+	t.Run("other families are intact", func(t *testing.T) {
+		other, err := refresher.Issue("user")
+		require.NoError(t, err)
+
+		_, next, err := refresher.Rotate([]byte(other))
+		require.NoError(t, err)
+		_, _, err = refresher.Rotate([]byte(next))
+		require.NoError(t, err)
+	})
+}
+
+func TestRefresher_Revoke(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	refresher, _ := newTestRefresher(t, clock)
+
+	s, err := refresher.Issue("user")
+	require.NoError(t, err)
+	require.NoError(t, refresher.Revoke([]byte(s)))
+
+	_, _, err = refresher.Rotate([]byte(s))
+	assert.True(t, errors.Is(err, ErrRefreshFamilyRevoked))
+	assert.Error(t, refresher.Revoke([]byte("bad")))
+}
+
+func TestRefresher_Invalid(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	refresher, _ := newTestRefresher(t, clock)
+
+	t.Run("expired", func(t *testing.T) {
+		s, err := refresher.Issue("user")
+		require.NoError(t, err)
+
+		clock := &testClock{now: clock.now.Add(48 * time.Hour)}
+		expired, _ := newTestRefresher(t, clock)
+		_, _, err = expired.Rotate([]byte(s))
+		assert.True(t, errors.Is(err, ErrTokenExpired))
+	})
+	t.Run("access token", func(t *testing.T) {
+		s, err := Build(alg.HS256).Issuer("auth").ExpiresIn(time.Hour).RandomId().Clock(clock.Now).WriteString()
+		require.NoError(t, err)
+
+		_, _, err = refresher.Rotate([]byte(s))
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+	})
+	t.Run("no family", func(t *testing.T) {
+		s, err := Build(alg.HS256).Type(RefreshTokenType).Issuer("auth").ExpiresIn(time.Hour).RandomId().Clock(clock.Now).WriteString()
+		require.NoError(t, err)
+
+		_, _, err = refresher.Rotate([]byte(s))
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+	t.Run("unknown family", func(t *testing.T) {
+		s, err := Build(alg.HS256).Type(RefreshTokenType).Issuer("auth").ExpiresIn(time.Hour).RandomId().
+			Claim(RefreshFamilyClaim, "forged").Clock(clock.Now).WriteString()
+		require.NoError(t, err)
+
+		_, _, err = refresher.Rotate([]byte(s))
+		assert.True(t, errors.Is(err, ErrRefreshFamilyRevoked))
+	})
+	t.Run("template without ttl", func(t *testing.T) {
+		refresher := NewRefresher(NewTemplate(alg.HS256), NewMemoryRefreshStore())
+		_, err := refresher.Issue("user")
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+}
+
+type failingSigner struct {
+	alg.Signer
+}
+
+func (s failingSigner) Sign([]byte) ([]byte, error) {
+	return nil, errors.New("signer is unavailable")
+}
+
+func TestRefresher_SigningFailure(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	refresher, store := newTestRefresher(t, clock)
+	hs256, err := alg.NewHmacSha(alg.HS256, testSecret)
+	require.NoError(t, err)
+	defer registerTestHmac(t)
+
+	s, err := refresher.Issue("user")
+	require.NoError(t, err)
+
+	Register(alg.HS256, hs256, failingSigner{hs256})
+	_, err = refresher.Issue("user")
+	assert.Error(t, err)
+	assert.Len(t, store.families, 1)
+
+	_, _, err = refresher.Rotate([]byte(s))
+	assert.Error(t, err)
+
+	registerTestHmac(t)
+	_, _, err = refresher.Rotate([]byte(s))
+	assert.NoError(t, err)
+}
+
+func TestMemoryRefreshStore(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryRefreshStore(WithRefreshStoreClock(clock.Now))
+
+	require.NoError(t, store.CreateFamily("a", "1", clock.now.Add(time.Hour)))
+	assert.Error(t, store.CreateFamily("a", "2", clock.now.Add(time.Hour)))
+	require.NoError(t, store.Rotate("a", "1", "2", clock.now.Add(2*time.Hour)))
+
+	clock.now = clock.now.Add(90 * time.Minute)
+	require.NoError(t, store.Rotate("a", "2", "3", clock.now.Add(time.Hour)))
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	assert.True(t, errors.Is(store.Rotate("a", "3", "4", clock.now), ErrRefreshFamilyRevoked))
+
+	require.NoError(t, store.CreateFamily("b", "1", clock.now.Add(time.Hour)))
+	assert.Len(t, store.families, 1)
+	require.NoError(t, store.RevokeFamily("missing"))
+}
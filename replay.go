@@ -0,0 +1,173 @@
+package jwt
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTokenReplayed   = errors.New("token is already used")
+	ErrReplayCacheFull = errors.New("replay cache is full")
+)
+
+// ReplayCache remembers identifiers of used tokens
+type ReplayCache interface {
+	// Use marks id as used until expiresAt. It returns false if id is already used and not expired yet
+	Use(id string, expiresAt time.Time) (bool, error)
+}
+
+// PreventReplay rejects tokens whose "jti" was already seen by cache with ErrTokenReplayed.
+// Tokens must have "jti" and "exp", the id is remembered until "exp" plus leeway.
+// The token is marked as used only if all other checks have passed
+func PreventReplay(cache ReplayCache) ValidatorOption {
+	return func(v *Validator) {
+		v.replayCache = cache
+	}
+}
+
+func (v Validator) checkReplay(c Claims) error {
+	if len(c.Id) == 0 {
+		return fmt.Errorf("%w: jti", ErrMissingClaim)
+	}
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("%w: exp", ErrMissingClaim)
+	}
+
+	ok, err := v.replayCache.Use(replayKey(c), c.ExpiresAt.Add(v.leeway))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTokenReplayed
+	}
+
+	return nil
+}
+
+// replayKey scopes token id by issuer, so equal ids of different issuers do not collide
+func replayKey(c Claims) string {
+	return c.Issuer + "\x00" + c.Id
+}
+
+// ReplayCacheOption configures MemoryReplayCache
+type ReplayCacheOption func(c *MemoryReplayCache)
+
+// WithReplayCacheClock replaces time source used to evict expired ids, time.Now is used by default
+func WithReplayCacheClock(clock func() time.Time) ReplayCacheOption {
+	return func(c *MemoryReplayCache) {
+		c.clock = clock
+	}
+}
+
+// WithReplayCacheOverflowEviction makes full cache forget the id closest to its expiration instead of
+// rejecting new ids with ErrReplayCacheFull. A flood of valid tokens can not lock out other clients then,
+// at the cost of replay protection of the oldest ids during the flood
+func WithReplayCacheOverflowEviction() ReplayCacheOption {
+	return func(c *MemoryReplayCache) {
+		c.evictOnOverflow = true
+	}
+}
+
+// MemoryReplayCache is ReplayCache keeping at most capacity unexpired ids in memory.
+// Expired ids are evicted on every use. When the cache is full, new ids are rejected
+// with ErrReplayCacheFull instead of forgetting unexpired ones, unless WithReplayCacheOverflowEviction
+// is used. It is safe for concurrent use
+type MemoryReplayCache struct {
+	mutex           sync.Mutex
+	capacity        int
+	evictOnOverflow bool
+	clock           func() time.Time
+	expiry          map[string]time.Time
+	queue           replayQueue
+}
+
+// NewMemoryReplayCache returns empty MemoryReplayCache holding at most capacity ids
+func NewMemoryReplayCache(capacity int, options ...ReplayCacheOption) *MemoryReplayCache {
+	c := &MemoryReplayCache{
+		capacity: capacity,
+		clock:    time.Now,
+		expiry:   make(map[string]time.Time),
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+func (c *MemoryReplayCache) Use(id string, expiresAt time.Time) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.clock()
+	c.evict(now)
+
+	if _, ok := c.expiry[id]; ok {
+		return false, nil
+	}
+	if !expiresAt.After(now) {
+		// already expired tokens are rejected by the validator, there is nothing to remember
+		return true, nil
+	}
+	if len(c.expiry) >= c.capacity {
+		if !c.evictOnOverflow || len(c.queue) == 0 {
+			return false, ErrReplayCacheFull
+		}
+
+		entry := heap.Pop(&c.queue).(replayEntry)
+		delete(c.expiry, entry.id)
+	}
+
+	c.expiry[id] = expiresAt
+	heap.Push(&c.queue, replayEntry{id: id, expiresAt: expiresAt})
+	return true, nil
+}
+
+// Len returns number of remembered ids
+func (c *MemoryReplayCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.expiry)
+}
+
+func (c *MemoryReplayCache) evict(now time.Time) {
+	for len(c.queue) > 0 && !c.queue[0].expiresAt.After(now) {
+		entry := heap.Pop(&c.queue).(replayEntry)
+		delete(c.expiry, entry.id)
+	}
+}
+
+type replayEntry struct {
+	id        string
+	expiresAt time.Time
+}
+
+// replayQueue is min-heap of entries ordered by expiration time
+type replayQueue []replayEntry
+
+func (q replayQueue) Len() int {
+	return len(q)
+}
+
+func (q replayQueue) Less(i, j int) bool {
+	return q[i].expiresAt.Before(q[j].expiresAt)
+}
+
+func (q replayQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *replayQueue) Push(x interface{}) {
+	*q = append(*q, x.(replayEntry))
+}
+
+func (q *replayQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
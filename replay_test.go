@@ -0,0 +1,196 @@
+package jwt
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	t.Run("use once", func(t *testing.T) {
+		cache := NewMemoryReplayCache(10, WithReplayCacheClock(clock.Now))
+
+		ok, err := cache.Use("a", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = cache.Use("a", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = cache.Use("b", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 2, cache.Len())
+	})
+	t.Run("eviction", func(t *testing.T) {
+		clock := &testClock{now: clock.now}
+		cache := NewMemoryReplayCache(10, WithReplayCacheClock(clock.Now))
+
+		_, err := cache.Use("a", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		_, err = cache.Use("b", clock.now.Add(time.Hour))
+		require.NoError(t, err)
+
+		clock.now = clock.now.Add(time.Minute)
+		ok, err := cache.Use("c", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 2, cache.Len())
+
+		ok, err = cache.Use("b", clock.now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("expired", func(t *testing.T) {
+		cache := NewMemoryReplayCache(10, WithReplayCacheClock(clock.Now))
+
+		ok, err := cache.Use("a", clock.now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+	t.Run("capacity", func(t *testing.T) {
+		clock := &testClock{now: clock.now}
+		cache := NewMemoryReplayCache(2, WithReplayCacheClock(clock.Now))
+
+		for i := 0; i < 2; i++ {
+			ok, err := cache.Use(strconv.Itoa(i), clock.now.Add(time.Minute))
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		_, err := cache.Use("2", clock.now.Add(time.Minute))
+		assert.True(t, errors.Is(err, ErrReplayCacheFull))
+
+		ok, err := cache.Use("1", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		clock.now = clock.now.Add(2 * time.Minute)
+		ok, err = cache.Use("2", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("overflow eviction", func(t *testing.T) {
+		cache := NewMemoryReplayCache(2, WithReplayCacheClock(clock.Now), WithReplayCacheOverflowEviction())
+
+		_, err := cache.Use("a", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		_, err = cache.Use("b", clock.now.Add(2*time.Minute))
+		require.NoError(t, err)
+
+		ok, err := cache.Use("c", clock.now.Add(3*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 2, cache.Len())
+
+		ok, err = cache.Use("b", clock.now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = cache.Use("a", clock.now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = NewMemoryReplayCache(0, WithReplayCacheOverflowEviction()).Use("a", time.Now().Add(time.Minute))
+		assert.True(t, errors.Is(err, ErrReplayCacheFull))
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		cache := NewMemoryReplayCache(10)
+
+		var used int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := cache.Use("a", time.Now().Add(time.Minute))
+				if err == nil && ok {
+					atomic.AddInt32(&used, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), used)
+	})
+}
+
+func TestPreventReplay(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewMemoryReplayCache(10, WithReplayCacheClock(clock.Now))
+	validator := NewValidator(
+		WithClock(clock.Now),
+		WithLeeway(time.Minute),
+		WithIssuer("issuer"),
+		PreventReplay(cache),
+	)
+
+	token := NewToken(alg.HS256)
+	token.Claims.Issuer = "issuer"
+	token.Claims.Id = "id"
+	token.Claims.ExpiresAt = NewPosixTime(clock.now.Add(time.Minute))
+
+	t.Run("replayed", func(t *testing.T) {
+		require.NoError(t, validator.Validate(token))
+
+		err := validator.Validate(token)
+		assert.True(t, errors.Is(err, ErrTokenReplayed))
+	})
+	t.Run("remembered with leeway", func(t *testing.T) {
+		clock.now = clock.now.Add(90 * time.Second)
+		err := validator.Validate(token)
+		assert.True(t, errors.Is(err, ErrTokenReplayed))
+	})
+	t.Run("invalid tokens are not remembered", func(t *testing.T) {
+		other := token
+		other.Claims.Id = "other"
+		other.Claims.ExpiresAt = NewPosixTime(clock.now.Add(time.Hour))
+		other.Claims.Issuer = "stranger"
+		assert.True(t, errors.Is(validator.Validate(other), ErrIssuerMismatch))
+
+		other.Claims.Issuer = "issuer"
+		assert.NoError(t, validator.Validate(other))
+	})
+	t.Run("issuer scoped", func(t *testing.T) {
+		cache := NewMemoryReplayCache(10, WithReplayCacheClock(clock.Now))
+		validator := NewValidator(WithClock(clock.Now), PreventReplay(cache))
+
+		first := NewToken(alg.HS256)
+		first.Claims.Issuer = "a"
+		first.Claims.Id = "id"
+		first.Claims.ExpiresAt = NewPosixTime(clock.now.Add(time.Minute))
+		second := first
+		second.Claims.Issuer = "b"
+
+		assert.NoError(t, validator.Validate(first))
+		assert.NoError(t, validator.Validate(second))
+	})
+	t.Run("required claims", func(t *testing.T) {
+		noId := token
+		noId.Claims.Id = ""
+		assert.True(t, errors.Is(validator.Validate(noId), ErrMissingClaim))
+
+		noExp := token
+		noExp.Claims.ExpiresAt = nil
+		assert.True(t, errors.Is(validator.Validate(noExp), ErrMissingClaim))
+	})
+}
@@ -0,0 +1,260 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTokenRevoked = errors.New("token is revoked")
+)
+
+// RevocationList stores revoked tokens. Implementations backed by Redis, SQL or other storages
+// must be safe for concurrent use
+type RevocationList interface {
+	// RevokeId revokes token with "jti" id, the record may be dropped after expiresAt
+	RevokeId(id string, expiresAt time.Time) error
+	// RevokeSubject revokes tokens with "sub" subject issued not after the moment (with second precision),
+	// tokens without "iat" included
+	RevokeSubject(subject string, issuedBefore time.Time) error
+	// RevokeKey revokes tokens with "kid" header keyId
+	RevokeKey(keyId string) error
+	// IsRevoked reports whether the token is revoked by any of its "jti", "sub" or "kid"
+	IsRevoked(t Token) (bool, error)
+}
+
+// CheckRevocation rejects tokens revoked in list with ErrTokenRevoked
+func CheckRevocation(list RevocationList) ValidatorOption {
+	return WithCheck(func(t Token) error {
+		revoked, err := list.IsRevoked(t)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+
+		return nil
+	})
+}
+
+// RevocationOption configures MemoryRevocationList and FileRevocationList
+type RevocationOption func(l *MemoryRevocationList)
+
+// WithRevocationClock replaces time source used to drop expired records, time.Now is used by default
+func WithRevocationClock(clock func() time.Time) RevocationOption {
+	return func(l *MemoryRevocationList) {
+		l.clock = clock
+	}
+}
+
+// MemoryRevocationList is RevocationList kept in memory, records of expired ids are dropped on revocation
+type MemoryRevocationList struct {
+	mutex       sync.RWMutex
+	clock       func() time.Time
+	revocations revocations
+}
+
+// revocations is serializable state of revocation list
+type revocations struct {
+	Ids      map[string]PosixTime `json:"ids"`
+	Subjects map[string]PosixTime `json:"subjects"`
+	Keys     map[string]bool      `json:"keys"`
+}
+
+// NewMemoryRevocationList returns empty MemoryRevocationList
+func NewMemoryRevocationList(options ...RevocationOption) *MemoryRevocationList {
+	l := &MemoryRevocationList{
+		clock: time.Now,
+		revocations: revocations{
+			Ids:      make(map[string]PosixTime),
+			Subjects: make(map[string]PosixTime),
+			Keys:     make(map[string]bool),
+		},
+	}
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+func (l *MemoryRevocationList) RevokeId(id string, expiresAt time.Time) error {
+	return l.update(func(r *revocations) {
+		now := l.clock()
+		for revoked, exp := range r.Ids {
+			if exp.Before(now) {
+				delete(r.Ids, revoked)
+			}
+		}
+
+		r.Ids[id] = PosixTime{Time: expiresAt}
+	})
+}
+
+func (l *MemoryRevocationList) RevokeSubject(subject string, issuedBefore time.Time) error {
+	return l.update(func(r *revocations) {
+		issuedBefore = issuedBefore.Truncate(time.Second)
+		if before, ok := r.Subjects[subject]; !ok || before.Before(issuedBefore) {
+			r.Subjects[subject] = PosixTime{Time: issuedBefore}
+		}
+	})
+}
+
+func (l *MemoryRevocationList) RevokeKey(keyId string) error {
+	return l.update(func(r *revocations) {
+		r.Keys[keyId] = true
+	})
+}
+
+func (l *MemoryRevocationList) IsRevoked(t Token) (bool, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	r := l.revocations
+	if _, ok := r.Ids[t.Claims.Id]; ok && len(t.Claims.Id) > 0 {
+		return true, nil
+	}
+	if r.Keys[t.Header.KeyId] && len(t.Header.KeyId) > 0 {
+		return true, nil
+	}
+
+	before, ok := r.Subjects[t.Claims.Subject]
+	if ok && len(t.Claims.Subject) > 0 {
+		issuedAt := t.Claims.IssuedAt
+		return issuedAt == nil || !issuedAt.After(before.Time), nil
+	}
+
+	return false, nil
+}
+
+func (l *MemoryRevocationList) update(change func(r *revocations)) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	change(&l.revocations)
+	return nil
+}
+
+// FileRevocationList is MemoryRevocationList persisted to JSON file after every revocation.
+// It is intended for single node deployments, the file must not be shared between processes
+type FileRevocationList struct {
+	*MemoryRevocationList
+	path       string
+	writeMutex sync.Mutex
+}
+
+// OpenFileRevocationList loads revocations from the file at path, missing file means empty list
+func OpenFileRevocationList(path string, options ...RevocationOption) (*FileRevocationList, error) {
+	l := &FileRevocationList{
+		MemoryRevocationList: NewMemoryRevocationList(options...),
+		path:                 path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &l.revocations); err != nil {
+		return nil, err
+	}
+	l.revocations.init()
+
+	return l, nil
+}
+
+func (l *FileRevocationList) RevokeId(id string, expiresAt time.Time) error {
+	return l.persist(func() error {
+		return l.MemoryRevocationList.RevokeId(id, expiresAt)
+	})
+}
+
+func (l *FileRevocationList) RevokeSubject(subject string, issuedBefore time.Time) error {
+	return l.persist(func() error {
+		return l.MemoryRevocationList.RevokeSubject(subject, issuedBefore)
+	})
+}
+
+func (l *FileRevocationList) RevokeKey(keyId string) error {
+	return l.persist(func() error {
+		return l.MemoryRevocationList.RevokeKey(keyId)
+	})
+}
+
+// persist applies revocation and atomically replaces the file with the new state.
+// The temp file is synced before rename and the directory after it, so the state survives a crash
+func (l *FileRevocationList) persist(revoke func() error) error {
+	l.writeMutex.Lock()
+	defer l.writeMutex.Unlock()
+
+	if err := revoke(); err != nil {
+		return err
+	}
+
+	l.mutex.RLock()
+	data, err := json.Marshal(l.revocations)
+	l.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(temp.Name())
+	}()
+
+	if _, err = temp.Write(data); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(temp.Name(), l.path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(l.path))
+}
+
+// syncDir flushes directory entries, e.g. a rename, to disk
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if err = dir.Sync(); err != nil {
+		_ = dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
+
+func (r *revocations) init() {
+	if r.Ids == nil {
+		r.Ids = make(map[string]PosixTime)
+	}
+	if r.Subjects == nil {
+		r.Subjects = make(map[string]PosixTime)
+	}
+	if r.Keys == nil {
+		r.Keys = make(map[string]bool)
+	}
+}
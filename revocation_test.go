@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRevocationTestToken(id, subject, keyId string, issuedAt time.Time) Token {
+	token := NewToken(alg.HS256)
+	token.Header.KeyId = keyId
+	token.Claims.Id = id
+	token.Claims.Subject = subject
+	token.Claims.IssuedAt = NewPosixTime(issuedAt)
+	return token
+}
+
+func testRevocationList(t *testing.T, list RevocationList, clock *testClock) {
+	t.Helper()
+	now := clock.now
+
+	require.NoError(t, list.RevokeId("revoked", now.Add(time.Hour)))
+	require.NoError(t, list.RevokeSubject("user", now))
+	require.NoError(t, list.RevokeKey("old"))
+
+	tests := []struct {
+		name    string
+		token   Token
+		revoked bool
+	}{
+		{"valid", newRevocationTestToken("id", "other", "new", now), false},
+		{"by id", newRevocationTestToken("revoked", "other", "new", now), true},
+		{"by key", newRevocationTestToken("id", "other", "old", now), true},
+		{"by subject", newRevocationTestToken("id", "user", "new", now.Add(-time.Minute)), true},
+		{"by subject same second", newRevocationTestToken("id", "user", "new", now), true},
+		{"subject issued later", newRevocationTestToken("id", "user", "new", now.Add(time.Second)), false},
+		{"empty", newRevocationTestToken("", "", "", now), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revoked, err := list.IsRevoked(test.token)
+			require.NoError(t, err)
+			assert.Equal(t, test.revoked, revoked)
+		})
+	}
+
+	t.Run("without iat", func(t *testing.T) {
+		token := newRevocationTestToken("id", "user", "new", now)
+		token.Claims.IssuedAt = nil
+
+		revoked, err := list.IsRevoked(token)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+	t.Run("earlier subject revocation is ignored", func(t *testing.T) {
+		require.NoError(t, list.RevokeSubject("user", now.Add(-time.Hour)))
+
+		revoked, err := list.IsRevoked(newRevocationTestToken("id", "user", "new", now.Add(-time.Minute)))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestMemoryRevocationList(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	list := NewMemoryRevocationList(WithRevocationClock(clock.Now))
+	testRevocationList(t, list, clock)
+
+	t.Run("expired ids are dropped", func(t *testing.T) {
+		clock.now = clock.now.Add(2 * time.Hour)
+		require.NoError(t, list.RevokeId("next", clock.now.Add(time.Hour)))
+
+		assert.Len(t, list.revocations.Ids, 1)
+		revoked, err := list.IsRevoked(newRevocationTestToken("revoked", "", "", clock.now))
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestFileRevocationList(t *testing.T) {
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "revoked.json")
+
+	list, err := OpenFileRevocationList(path, WithRevocationClock(clock.Now))
+	require.NoError(t, err)
+	testRevocationList(t, list, clock)
+
+	t.Run("reopen", func(t *testing.T) {
+		reopened, err := OpenFileRevocationList(path, WithRevocationClock(clock.Now))
+		require.NoError(t, err)
+		assert.Equal(t, list.revocations.Keys, reopened.revocations.Keys)
+		assert.Len(t, reopened.revocations.Ids, 1)
+		assert.Len(t, reopened.revocations.Subjects, 1)
+
+		revoked, err := reopened.IsRevoked(newRevocationTestToken("revoked", "", "", clock.now))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+	t.Run("bad file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bad.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+		_, err := OpenFileRevocationList(path)
+		assert.Error(t, err)
+
+		_, err = OpenFileRevocationList(filepath.Dir(path))
+		assert.Error(t, err)
+	})
+	t.Run("partial file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "partial.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"keys":{"old":true}}`), 0600))
+
+		list, err := OpenFileRevocationList(path)
+		require.NoError(t, err)
+		require.NoError(t, list.RevokeId("id", time.Now().Add(time.Hour)))
+		require.NoError(t, list.RevokeSubject("user", time.Now()))
+
+		revoked, err := list.IsRevoked(newRevocationTestToken("", "", "old", time.Now()))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+	t.Run("unwritable", func(t *testing.T) {
+		list, err := OpenFileRevocationList(filepath.Join(t.TempDir(), "missing", "revoked.json"))
+		require.NoError(t, err)
+		assert.Error(t, list.RevokeKey("old"))
+	})
+}
+
+func TestCheckRevocation(t *testing.T) {
+	list := NewMemoryRevocationList()
+	require.NoError(t, list.RevokeKey("old"))
+	validator := NewValidator(CheckRevocation(list))
+
+	assert.NoError(t, validator.Validate(newRevocationTestToken("id", "user", "new", time.Now())))
+
+	err := validator.Validate(newRevocationTestToken("id", "user", "old", time.Now()))
+	assert.True(t, errors.Is(err, ErrTokenRevoked))
+}
+
+func TestSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, syncDir(dir))
+	require.Error(t, syncDir(filepath.Join(dir, "missing")))
+}
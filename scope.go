@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ScopeClaim is space-delimited scope string defined by RFC 8693
+	ScopeClaim = "scope"
+	// ScpClaim is scope array (or string) used by some authorization servers
+	ScpClaim = "scp"
+)
+
+// Scopes returns scopes of the token from "scope" claim (space-delimited string)
+// or "scp" claim (array of strings or space-delimited string). It returns nil if both are missing
+func (c Claims) Scopes() ([]string, error) {
+	fields, err := c.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	if raw, ok := fields.get(ScopeClaim); ok {
+		var scope string
+		if err = json.Unmarshal(raw, &scope); err != nil {
+			return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidClaim, ScopeClaim)
+		}
+
+		return strings.Fields(scope), nil
+	}
+	if raw, ok := fields.get(ScpClaim); ok {
+		scopes, err := decodeStrings(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, ScpClaim)
+		}
+
+		return scopes, nil
+	}
+
+	return nil, nil
+}
+
+// HasScope reports whether the token grants the scope, malformed scope claims grant nothing
+func (c Claims) HasScope(scope string) bool {
+	scopes, err := c.Scopes()
+	if err != nil {
+		return false
+	}
+
+	return containsConstTime(scopes, scope)
+}
+
+// Roles returns roles found at dot-separated path of nested claims, e.g. "realm_access.roles".
+// The value must be an array of strings or a single space-delimited string.
+// It returns nil if the path does not exist
+func (c Claims) Roles(path string) ([]string, error) {
+	raw, err := c.lookup(path)
+	if err != nil {
+		if errors.Is(err, ErrMissingClaim) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	roles, err := decodeStrings(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+
+	return roles, nil
+}
+
+// RequireAllScopes returns Authorizer accepting tokens granting every of scopes
+func RequireAllScopes(scopes ...string) Authorizer {
+	return func(_ *http.Request, t Token) error {
+		granted, err := t.Claims.Scopes()
+		if err != nil {
+			return err
+		}
+
+		for _, scope := range scopes {
+			if !containsConstTime(granted, scope) {
+				return InsufficientScopeError{Scope: scopes}
+			}
+		}
+
+		return nil
+	}
+}
+
+// RequireAnyScope returns Authorizer accepting tokens granting at least one of scopes
+func RequireAnyScope(scopes ...string) Authorizer {
+	return func(_ *http.Request, t Token) error {
+		granted, err := t.Claims.Scopes()
+		if err != nil {
+			return err
+		}
+
+		for _, scope := range scopes {
+			if containsConstTime(granted, scope) {
+				return nil
+			}
+		}
+
+		return InsufficientScopeError{Scope: scopes}
+	}
+}
+
+// RequireAllRoles returns Authorizer accepting tokens having every of roles at path, see Claims.Roles
+func RequireAllRoles(path string, roles ...string) Authorizer {
+	return func(_ *http.Request, t Token) error {
+		granted, err := t.Claims.Roles(path)
+		if err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			if !containsConstTime(granted, role) {
+				return InsufficientScopeError{}
+			}
+		}
+
+		return nil
+	}
+}
+
+// RequireAnyRole returns Authorizer accepting tokens having at least one of roles at path, see Claims.Roles
+func RequireAnyRole(path string, roles ...string) Authorizer {
+	return func(_ *http.Request, t Token) error {
+		granted, err := t.Claims.Roles(path)
+		if err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			if containsConstTime(granted, role) {
+				return nil
+			}
+		}
+
+		return InsufficientScopeError{}
+	}
+}
+
+// decodeStrings decodes array of strings or space-delimited string
+func decodeStrings(raw json.RawMessage) ([]string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.Fields(s), nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("%w: expected string or array of strings", ErrInvalidClaim)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,164 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScopedToken(t *testing.T, claims map[string]interface{}) Token {
+	t.Helper()
+
+	token := NewToken(alg.HS256)
+	require.NoError(t, token.Claims.Set(claims))
+	return token
+}
+
+func TestClaims_Scopes(t *testing.T) {
+	t.Run("scope", func(t *testing.T) {
+		token := newScopedToken(t, map[string]interface{}{"scope": " read  write "})
+		scopes, err := token.Claims.Scopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read", "write"}, scopes)
+		assert.True(t, token.Claims.HasScope("write"))
+		assert.False(t, token.Claims.HasScope("delete"))
+	})
+	t.Run("scp", func(t *testing.T) {
+		token := newScopedToken(t, map[string]interface{}{"scp": []string{"read", "write"}})
+		scopes, err := token.Claims.Scopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read", "write"}, scopes)
+
+		token = newScopedToken(t, map[string]interface{}{"scp": "read write"})
+		scopes, err = token.Claims.Scopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read", "write"}, scopes)
+	})
+	t.Run("scope preferred", func(t *testing.T) {
+		token := newScopedToken(t, map[string]interface{}{"scope": "read", "scp": []string{"write"}})
+		scopes, err := token.Claims.Scopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read"}, scopes)
+	})
+	t.Run("missing", func(t *testing.T) {
+		scopes, err := NewToken(alg.HS256).Claims.Scopes()
+		require.NoError(t, err)
+		assert.Nil(t, scopes)
+
+		scopes, err = newScopedToken(t, map[string]interface{}{"sub": "user"}).Claims.Scopes()
+		require.NoError(t, err)
+		assert.Nil(t, scopes)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		token := newScopedToken(t, map[string]interface{}{"scope": []string{"read"}})
+		_, err := token.Claims.Scopes()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+		assert.False(t, token.Claims.HasScope("read"))
+
+		token = newScopedToken(t, map[string]interface{}{"scp": 1})
+		_, err = token.Claims.Scopes()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+}
+
+func TestClaims_Roles(t *testing.T) {
+	token := newScopedToken(t, map[string]interface{}{
+		"realm_access": map[string]interface{}{
+			"roles": []string{"admin", "user"},
+		},
+		"resource_access": map[string]interface{}{
+			"api": map[string]interface{}{
+				"roles": "reader writer",
+			},
+		},
+		"groups": 1,
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		roles, err := token.Claims.Roles("realm_access.roles")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin", "user"}, roles)
+
+		roles, err = token.Claims.Roles("resource_access.api.roles")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reader", "writer"}, roles)
+	})
+	t.Run("missing", func(t *testing.T) {
+		roles, err := token.Claims.Roles("resource_access.web.roles")
+		require.NoError(t, err)
+		assert.Nil(t, roles)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, err := token.Claims.Roles("groups")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+		_, err = token.Claims.Roles("groups.roles")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+		_, err = token.Claims.Roles("realm_access")
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+}
+
+func TestScopeAuthorizers(t *testing.T) {
+	token := newScopedToken(t, map[string]interface{}{
+		"scope":        "read write",
+		"realm_access": map[string]interface{}{"roles": []string{"user"}},
+	})
+
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		scope      []string
+		allowed    bool
+	}{
+		{"all scopes", RequireAllScopes("read", "write"), nil, true},
+		{"all scopes missing", RequireAllScopes("read", "delete"), []string{"read", "delete"}, false},
+		{"any scope", RequireAnyScope("delete", "write"), nil, true},
+		{"any scope missing", RequireAnyScope("delete", "admin"), []string{"delete", "admin"}, false},
+		{"all roles", RequireAllRoles("realm_access.roles", "user"), nil, true},
+		{"all roles missing", RequireAllRoles("realm_access.roles", "user", "admin"), nil, false},
+		{"any role", RequireAnyRole("realm_access.roles", "admin", "user"), nil, true},
+		{"any role missing", RequireAnyRole("resource_access.api.roles", "user"), nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.authorizer(nil, token)
+			if test.allowed {
+				assert.NoError(t, err)
+				return
+			}
+
+			var scopeErr InsufficientScopeError
+			require.True(t, errors.As(err, &scopeErr))
+			assert.Equal(t, test.scope, scopeErr.Scope)
+		})
+	}
+}
+
+func TestMiddleware_Scopes(t *testing.T) {
+	registerTestHmac(t)
+
+	token := newScopedToken(t, map[string]interface{}{"sub": "user", "scope": "read"})
+	s, err := token.WriteString()
+	require.NoError(t, err)
+
+	t.Run("allowed", func(t *testing.T) {
+		m := NewMiddleware(NewParser(), NewValidator(), WithAuthorizer(RequireAllScopes("read")))
+		w, seen := serveTestRequest(m, "Bearer "+s)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.NotNil(t, seen)
+	})
+	t.Run("forbidden", func(t *testing.T) {
+		m := NewMiddleware(NewParser(), NewValidator(), WithAuthorizer(RequireAnyScope("write", "admin")))
+		w, seen := serveTestRequest(m, "Bearer "+s)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="write admin"`, w.Header().Get("WWW-Authenticate"))
+		assert.Nil(t, seen)
+	})
+}
@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+const (
+	// EventsClaim is "events" claim of Security Event Token, RFC 8417
+	EventsClaim = "events"
+	// TransactionClaim is "txn" claim of Security Event Token, transaction identifier
+	TransactionClaim = "txn"
+	// TimeOfEventClaim is "toe" claim of Security Event Token, the time the event occurred
+	TimeOfEventClaim = "toe"
+)
+
+var (
+	ErrSecurityEventMisuse = errors.New("security event token must not be used as access or id token")
+)
+
+// Events is "events" claim of Security Event Token: event type URIs mapped to JSON object payloads
+type Events map[string]json.RawMessage
+
+// Has reports whether event of eventType is present
+func (e Events) Has(eventType string) bool {
+	_, ok := e[eventType]
+	return ok
+}
+
+// Decode unmarshals payload of eventType event into out
+func (e Events) Decode(eventType string, out interface{}) error {
+	payload, ok := e[eventType]
+	if !ok {
+		return fmt.Errorf("%w: event %s", ErrMissingClaim, eventType)
+	}
+
+	return json.Unmarshal(payload, out)
+}
+
+// Events returns "events" claim, every event payload must be a JSON object
+func (c Claims) Events() (Events, error) {
+	events := Events{}
+	if err := c.getValue(EventsClaim, &events, "object"); err != nil {
+		return nil, err
+	}
+
+	for eventType, payload := range events {
+		if !isJsonObject(payload) {
+			return nil, fmt.Errorf("%w: event %s must be an object", ErrInvalidClaim, eventType)
+		}
+	}
+
+	return events, nil
+}
+
+// AddEvent adds event of eventType to "events" claim, nil payload is written as empty object
+func (c *Claims) AddEvent(eventType string, payload interface{}) error {
+	events, err := c.Events()
+	if errors.Is(err, ErrMissingClaim) {
+		events, err = Events{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if payload == nil {
+		payload = struct{}{}
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if !isJsonObject(raw) {
+		return fmt.Errorf("%w: event %s must be an object", ErrInvalidClaim, eventType)
+	}
+
+	events[eventType] = raw
+	return c.Put(EventsClaim, events)
+}
+
+// NewSecurityEventTemplate returns Template of Security Event Tokens (RFC 8417) issued by issuer.
+// Tokens have "typ" secevent+jwt, "iss", "iat" and random "jti", building a token fails without events
+func NewSecurityEventTemplate(a alg.Algorithm, issuer string, options ...TemplateOption) Template {
+	options = append([]TemplateOption{
+		IssuedBy(issuer),
+		WithTemplateType(SecurityEventType),
+	}, options...)
+
+	t := NewTemplate(a, options...)
+	t.check = checkSecurityEventClaims
+	return t
+}
+
+// ValidateSecurityEvent configures Validator to check Security Event Tokens of issuer: "typ" must be
+// secevent+jwt, "iss", "iat", "jti" and at least one event are required, "aud" must contain one of audiences
+// if any is given. Parser must accept SecurityEventType, e.g. NewParser(RequireType(SecurityEventType))
+func ValidateSecurityEvent(issuer string, audiences ...string) ValidatorOption {
+	return func(v *Validator) {
+		WithIssuer(issuer)(v)
+		if len(audiences) > 0 {
+			WithAudience(audiences...)(v)
+		}
+		WithCheck(checkSecurityEvent)(v)
+	}
+}
+
+// RejectSecurityEvents rejects tokens with "events" claim, so Security Event Tokens, which usually
+// have no "exp", can not be used as access or ID tokens. ValidateAccessToken and ValidateIdToken include it
+func RejectSecurityEvents() ValidatorOption {
+	return WithCheck(rejectSecurityEvent)
+}
+
+func rejectSecurityEvent(t Token) error {
+	if t.Header.Type.Is(SecurityEventType) || t.Claims.Has(EventsClaim) {
+		return ErrSecurityEventMisuse
+	}
+
+	return nil
+}
+
+func checkSecurityEvent(t Token) error {
+	if !t.Header.Type.Is(SecurityEventType) {
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedType, t.Header.Type)
+	}
+
+	return checkSecurityEventClaims(t)
+}
+
+func checkSecurityEventClaims(t Token) error {
+	c := t.Claims
+	switch {
+	case len(c.Issuer) == 0:
+		return fmt.Errorf("%w: iss", ErrMissingClaim)
+	case c.IssuedAt == nil:
+		return fmt.Errorf("%w: iat", ErrMissingClaim)
+	case len(c.Id) == 0:
+		return fmt.Errorf("%w: jti", ErrMissingClaim)
+	}
+
+	events, err := c.Events()
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: events must not be empty", ErrInvalidClaim)
+	}
+
+	return nil
+}
+
+func isJsonObject(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '{'
+}
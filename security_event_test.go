@@ -0,0 +1,166 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSessionRevoked    = "https://schemas.openid.net/secevent/risc/event-type/sessions-revoked"
+	testCredentialChanged = "https://schemas.openid.net/secevent/caep/event-type/credential-change"
+)
+
+type testCredentialChange struct {
+	CredentialType string `json:"credential_type"`
+	ChangeType     string `json:"change_type"`
+}
+
+func TestClaims_Events(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		claims := newTestClaims(t, `{"events":{"https://example.com/a":{},"https://example.com/b":{"reason":"x"}}}`)
+		events, err := claims.Events()
+		require.NoError(t, err)
+		assert.True(t, events.Has("https://example.com/a"))
+		assert.False(t, events.Has("https://example.com/c"))
+
+		var payload struct {
+			Reason string `json:"reason"`
+		}
+		require.NoError(t, events.Decode("https://example.com/b", &payload))
+		assert.Equal(t, "x", payload.Reason)
+		assert.True(t, errors.Is(events.Decode("https://example.com/c", &payload), ErrMissingClaim))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, data := range []string{`{"events":[]}`, `{"events":null}`, `{"events":{"a":"b"}}`, `{"events":{"a":null}}`} {
+			_, err := newTestClaims(t, data).Events()
+			assert.True(t, errors.Is(err, ErrInvalidClaim), data)
+		}
+
+		_, err := Claims{}.Events()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+	t.Run("add", func(t *testing.T) {
+		claims := Claims{}
+		require.NoError(t, claims.AddEvent(testSessionRevoked, nil))
+		require.NoError(t, claims.AddEvent(testCredentialChanged, testCredentialChange{"password", "update"}))
+		assert.True(t, errors.Is(claims.AddEvent("https://example.com/a", "payload"), ErrInvalidClaim))
+
+		data, err := claims.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"events":{
+			"`+testSessionRevoked+`":{},
+			"`+testCredentialChanged+`":{"credential_type":"password","change_type":"update"}
+		}}`, string(data))
+	})
+}
+
+func TestSecurityEventTemplate(t *testing.T) {
+	registerTestHmac(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+	template := NewSecurityEventTemplate(alg.HS256, "https://idp.example.com", IssuedFor("https://rp.example.com"),
+		WithTemplateClock(clock))
+	parser := NewParser(RequireType(SecurityEventType))
+	validator := NewValidator(WithClock(clock), ValidateSecurityEvent("https://idp.example.com", "https://rp.example.com"))
+
+	s, err := template.Build().
+		Subject("user").
+		Transaction("txn").
+		TimeOfEvent(now.Add(-time.Minute)).
+		Event(testCredentialChanged, testCredentialChange{"password", "update"}).
+		WriteString()
+	require.NoError(t, err)
+
+	token, err := parser.Parse([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, validator.Validate(token))
+	assert.Equal(t, SecurityEventType, token.Header.Type)
+	assert.Nil(t, token.Claims.ExpiresAt)
+
+	events, err := token.Claims.Events()
+	require.NoError(t, err)
+	change := testCredentialChange{}
+	require.NoError(t, events.Decode(testCredentialChanged, &change))
+	assert.Equal(t, testCredentialChange{"password", "update"}, change)
+
+	toe, err := token.Claims.GetTime(TimeOfEventClaim)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute).Unix(), toe.Unix())
+
+	t.Run("no events", func(t *testing.T) {
+		_, err := template.Build().Subject("user").Token()
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+
+		_, err = template.Build().Claim(EventsClaim, map[string]interface{}{}).Token()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+
+		_, err = template.Build().Event(testSessionRevoked, 1).Token()
+		assert.True(t, errors.Is(err, ErrInvalidClaim))
+	})
+	t.Run("not a jwt", func(t *testing.T) {
+		_, err := Parse([]byte(s))
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+	})
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(token *Token)
+			err    error
+		}{
+			{"type", func(token *Token) { token.Header.Type = JsonWebTokenType }, ErrUnsupportedType},
+			{"issuer", func(token *Token) { token.Claims.Issuer = "other" }, ErrIssuerMismatch},
+			{"audience", func(token *Token) { token.Claims.Audience = nil }, ErrAudienceMismatch},
+			{"iat", func(token *Token) { token.Claims.IssuedAt = nil }, ErrMissingClaim},
+			{"jti", func(token *Token) { token.Claims.Id = "" }, ErrMissingClaim},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				token, err := parser.Parse([]byte(s))
+				require.NoError(t, err)
+				test.modify(&token)
+				assert.True(t, errors.Is(validator.Validate(token), test.err))
+			})
+		}
+	})
+}
+
+func TestRejectSecurityEvents(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+
+	token, err := NewSecurityEventTemplate(alg.HS256, "https://auth.example.com", WithTemplateClock(clock)).Build().
+		Subject("user").
+		Audience("https://api.example.com").
+		ExpiresIn(time.Hour).
+		ClientId("client").
+		Event(testSessionRevoked, nil).
+		Token()
+	require.NoError(t, err)
+
+	validators := map[string]Validator{
+		"plain":        NewValidator(WithClock(clock), RejectSecurityEvents()),
+		"access token": NewValidator(WithClock(clock), ValidateAccessToken("https://auth.example.com", "https://api.example.com")),
+		"id token":     NewValidator(WithClock(clock), ValidateIdToken("https://auth.example.com", "https://api.example.com")),
+	}
+	for name, validator := range validators {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, errors.Is(validator.Validate(token), ErrSecurityEventMisuse))
+
+			untyped := token
+			untyped.Header.Type = AccessTokenType
+			assert.True(t, errors.Is(validator.Validate(untyped), ErrSecurityEventMisuse))
+		})
+	}
+
+	assert.NoError(t, NewValidator(RejectSecurityEvents()).Validate(NewToken(alg.HS256)))
+}
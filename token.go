@@ -28,23 +28,79 @@ func NewToken(a alg.Algorithm) Token {
 	}
 }
 
-func (t Token) Write() (*bytes.Buffer, error) {
-	headerJson, err := json.Marshal(t.Header)
+// WriteOption modifies the token right before it is signed with signer
+type WriteOption func(t *Token, signer alg.Signer) error
+
+// WithThumbprintKeyId sets "kid" header to base64url SHA-256 JWK thumbprint (RFC 7638) of the signer key.
+// Signer must implement alg.JWKProvider with asymmetric key, symmetric algorithms are rejected
+// as the thumbprint of a secret would be published in every token
+func WithThumbprintKeyId() WriteOption {
+	return func(t *Token, signer alg.Signer) error {
+		provider, ok := signer.(alg.JWKProvider)
+		if !ok {
+			return fmt.Errorf("signer of algorithm \"%s\" does not provide jwk", t.Header.Algorithm)
+		}
+
+		jwk, err := provider.JWK()
+		if err != nil {
+			return err
+		}
+		if jwk.KeyType == alg.KeyTypeOct {
+			return fmt.Errorf("thumbprint kid requires asymmetric algorithm, got \"%s\"", t.Header.Algorithm)
+		}
+
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return err
+		}
+
+		t.Header.KeyId = toBase64(thumbprint)
+		return nil
+	}
+}
+
+// Write signs the token with registered signer and returns its compact serialization.
+// If the signer is bound to certificates (alg.CertificateHolder) and "x5c" is not set,
+// the chain and its "x5t#S256" are added to the header
+func (t Token) Write(options ...WriteOption) (*bytes.Buffer, error) {
+	signer, err := t.prepare(options)
 	if err != nil {
 		return nil, err
 	}
-	headerText := toBase64(headerJson)
 
 	claimsJson, err := json.Marshal(t.Claims)
 	if err != nil {
 		return nil, err
 	}
-	claimsText := toBase64(claimsJson)
 
+	return sign(t.Header, claimsJson, signer)
+}
+
+// prepare returns registered signer and applies write options to the token
+func (t *Token) prepare(options []WriteOption) (alg.Signer, error) {
 	signer, ok := signers[t.Header.Algorithm]
 	if !ok {
 		return nil, fmt.Errorf("unknown algorithm \"%s\"", t.Header.Algorithm)
 	}
+	if holder, ok := signer.(alg.CertificateHolder); ok && len(t.Header.X509CertChain) == 0 {
+		t.SetCertificateChain(holder.Certificates()...)
+	}
+	for _, option := range options {
+		if err := option(t, signer); err != nil {
+			return nil, err
+		}
+	}
+
+	return signer, nil
+}
+
+func sign(header Header, claimsJson []byte, signer alg.Signer) (*bytes.Buffer, error) {
+	headerJson, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	headerText := toBase64(headerJson)
+	claimsText := toBase64(claimsJson)
 
 	result := new(bytes.Buffer)
 	result.Grow(len(headerText) + len(claimsText) + signer.Size() + len(dotBytes)*2)
@@ -64,8 +120,8 @@ func (t Token) Write() (*bytes.Buffer, error) {
 	return result, nil
 }
 
-func (t Token) WriteString() (string, error) {
-	buf, err := t.Write()
+func (t Token) WriteString(options ...WriteOption) (string, error) {
+	buf, err := t.Write(options...)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,9 @@
 package jwt
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"strings"
 	"testing"
 	"time"
@@ -30,7 +33,7 @@ func TestToken_UnmarshalClaims(t *testing.T) {
 }
 
 func TestToken_Write(t *testing.T) {
-	hs256, err := alg.NewHmacSha(alg.HS256, "secret")
+	hs256, err := alg.NewHmacSha(alg.HS256, "secret", alg.AllowWeakKeys())
 	require.NoError(t, err)
 
 	t.Run("bad algorithm", func(t *testing.T) {
@@ -161,3 +164,37 @@ func TestToken_Validate(t *testing.T) {
 	assert.Equal(t, StateInactive, token.ValidateNow())
 	assert.Equal(t, StateValid, token.Validate(time.Now().Add(6*time.Minute)))
 }
+
+func TestToken_WriteThumbprintKeyId(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	es256, err := alg.NewECDSA(alg.ES256, private, &private.PublicKey)
+	require.NoError(t, err)
+	Register(alg.ES256, es256, es256)
+
+	jwk, err := alg.NewJWK(&private.PublicKey)
+	require.NoError(t, err)
+	thumbprint, err := jwk.Thumbprint()
+	require.NoError(t, err)
+
+	s, err := NewToken(alg.ES256).WriteString(WithThumbprintKeyId())
+	require.NoError(t, err)
+
+	token, err := Parse([]byte(s))
+	require.NoError(t, err)
+	assert.Equal(t, toBase64(thumbprint), token.Header.KeyId)
+
+	t.Run("symmetric", func(t *testing.T) {
+		hs256, err := alg.NewHmacSha(alg.HS256, "0123456789abcdef0123456789abcdef")
+		require.NoError(t, err)
+		Register(alg.HS256, hs256, hs256)
+
+		_, err = NewToken(alg.HS256).Write(WithThumbprintKeyId())
+		require.Error(t, err)
+	})
+	t.Run("no jwk", func(t *testing.T) {
+		Register(alg.None, alg.NoneAlgorithm{}, alg.NoneAlgorithm{})
+		_, err := NewToken(alg.None).Write(WithThumbprintKeyId())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+)
+
+// ClaimSet is implemented by any type embedding BasicClaims
+type ClaimSet interface {
+	Registered() BasicClaims
+}
+
+// Registered returns registered claims, it makes types embedding BasicClaims implement ClaimSet
+func (c BasicClaims) Registered() BasicClaims {
+	return c
+}
+
+// TypedToken is a token with claims of type T decoded once during parsing.
+// Token name is kept for the untyped token, TypedToken[Claims] behaves the same way
+type TypedToken[T ClaimSet] struct {
+	Header    Header
+	Claims    T
+	signature []byte
+}
+
+// NewTypedToken returns TypedToken signed by algorithm a with claims
+func NewTypedToken[T ClaimSet](a alg.Algorithm, claims T) TypedToken[T] {
+	return TypedToken[T]{
+		Header: Header{
+			Algorithm: a,
+			Type:      JsonWebTokenType,
+		},
+		Claims: claims,
+	}
+}
+
+// ParseAs returns TypedToken parsed from byte array data, it is verified the same way as Parse does
+func ParseAs[T ClaimSet](data []byte, options ...ParseOption) (TypedToken[T], error) {
+	header, parts, err := NewParser(options...).verify(data)
+	if err != nil {
+		return TypedToken[T]{}, err
+	}
+
+	var claims T
+	if err = json.Unmarshal(parts.claims, &claims); err != nil {
+		return TypedToken[T]{}, err
+	}
+
+	return TypedToken[T]{
+		Header:    header,
+		Claims:    claims,
+		signature: parts.signature,
+	}, nil
+}
+
+// Typed decodes claims of already parsed token into T,
+// e.g. the one returned by Parser.Parse or stored in the request context by Middleware
+func Typed[T ClaimSet](t Token) (TypedToken[T], error) {
+	raw := t.Claims.raw
+	if len(raw) == 0 {
+		var err error
+		if raw, err = json.Marshal(t.Claims); err != nil {
+			return TypedToken[T]{}, err
+		}
+	}
+
+	var claims T
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return TypedToken[T]{}, err
+	}
+
+	return TypedToken[T]{
+		Header:    t.Header,
+		Claims:    claims,
+		signature: t.signature,
+	}, nil
+}
+
+// Token returns untyped Token with the same header and claims, e.g. to check it with Validator
+func (t TypedToken[T]) Token() (Token, error) {
+	raw, err := json.Marshal(t.Claims)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := Token{
+		Header:    t.Header,
+		signature: t.signature,
+	}
+	if err = json.Unmarshal(raw, &token.Claims); err != nil {
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
+// Write signs the token like Token.Write does, claims are marshalled from T directly.
+// Registered claims changed by options (e.g. "jti" of WithGeneratedId) replace the ones of T in the payload
+func (t TypedToken[T]) Write(options ...WriteOption) (*bytes.Buffer, error) {
+	registered := t.Claims.Registered()
+	token := Token{
+		Header: t.Header,
+		Claims: Claims{BasicClaims: registered},
+	}
+	signer, err := token.prepare(options)
+	if err != nil {
+		return nil, err
+	}
+
+	claimsJson, err := json.Marshal(t.Claims)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(registered, token.Claims.BasicClaims) {
+		claims := Claims{}
+		if err = json.Unmarshal(claimsJson, &claims); err != nil {
+			return nil, err
+		}
+		claims.BasicClaims = token.Claims.BasicClaims
+
+		if claimsJson, err = json.Marshal(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return sign(token.Header, claimsJson, signer)
+}
+
+func (t TypedToken[T]) WriteString(options ...WriteOption) (string, error) {
+	buf, err := t.Write(options...)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (t TypedToken[T]) Validate(moment time.Time) State {
+	return Token{Claims: Claims{BasicClaims: t.Claims.Registered()}}.Validate(moment)
+}
+
+func (t TypedToken[T]) ValidateNow() State {
+	return t.Validate(time.Now())
+}
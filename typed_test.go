@@ -0,0 +1,146 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viva-Victoria/bear-jwt/alg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestClaims struct {
+	BasicClaims
+	Name  string   `json:"name"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+func TestTypedToken_Write(t *testing.T) {
+	registerTestHmac(t)
+
+	exp := NewPosixTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	token := NewTypedToken(alg.HS256, typedTestClaims{
+		BasicClaims: BasicClaims{Subject: "user", ExpiresAt: exp},
+		Name:        "Bear",
+		Roles:       []string{"admin"},
+	})
+
+	s, err := token.WriteString()
+	require.NoError(t, err)
+
+	decoded, err := Decode([]byte(s))
+	require.NoError(t, err)
+	assert.Equal(t, `{"exp":1893456000,"sub":"user","name":"Bear","roles":["admin"]}`, string(decoded.Claims.raw))
+
+	t.Run("parse", func(t *testing.T) {
+		parsed, err := ParseAs[typedTestClaims]([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, token.Header, parsed.Header)
+		assert.Equal(t, "user", parsed.Claims.Subject)
+		assert.Equal(t, "Bear", parsed.Claims.Name)
+		assert.Equal(t, []string{"admin"}, parsed.Claims.Roles)
+		assert.Equal(t, exp.Unix(), parsed.Claims.ExpiresAt.Unix())
+		assert.Equal(t, StateValid, parsed.Validate(time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, StateExpired, parsed.Validate(time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+	t.Run("parse options", func(t *testing.T) {
+		_, err := ParseAs[typedTestClaims]([]byte(s), WithTypes(AccessTokenType))
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+
+		_, err = ParseAs[typedTestClaims]([]byte(s[:len(s)-2]))
+		assert.Error(t, err)
+	})
+	t.Run("write options", func(t *testing.T) {
+		s, err := token.WriteString(func(t *Token, _ alg.Signer) error {
+			t.Header.KeyId = "key"
+			return nil
+		})
+		require.NoError(t, err)
+
+		parsed, err := ParseAs[typedTestClaims]([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, "key", parsed.Header.KeyId)
+		assert.Empty(t, token.Header.KeyId)
+	})
+	t.Run("generated id", func(t *testing.T) {
+		s, err := token.WriteString(WithGeneratedId(func() (string, error) {
+			return "generated", nil
+		}))
+		require.NoError(t, err)
+
+		parsed, err := ParseAs[typedTestClaims]([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, "generated", parsed.Claims.Id)
+		assert.Equal(t, "Bear", parsed.Claims.Name)
+		assert.Empty(t, token.Claims.Id)
+
+		decoded, err := Decode([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, `{"exp":1893456000,"sub":"user","name":"Bear","roles":["admin"],"jti":"generated"}`, string(decoded.Claims.raw))
+	})
+}
+
+func TestTyped(t *testing.T) {
+	registerTestHmac(t)
+
+	token := NewToken(alg.HS256)
+	require.NoError(t, token.Claims.Set(map[string]interface{}{"sub": "user", "name": "Bear"}))
+	s, err := token.WriteString()
+	require.NoError(t, err)
+
+	parsed, err := Parse([]byte(s))
+	require.NoError(t, err)
+
+	typed, err := Typed[typedTestClaims](parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "user", typed.Claims.Subject)
+	assert.Equal(t, "Bear", typed.Claims.Name)
+
+	t.Run("registered only", func(t *testing.T) {
+		token := NewToken(alg.HS256)
+		token.Claims.Issuer = "issuer"
+
+		typed, err := Typed[typedTestClaims](token)
+		require.NoError(t, err)
+		assert.Equal(t, "issuer", typed.Claims.Issuer)
+	})
+	t.Run("untyped claims", func(t *testing.T) {
+		typed, err := Typed[Claims](parsed)
+		require.NoError(t, err)
+		assert.Equal(t, "user", typed.Claims.Subject)
+
+		var custom typedTestClaims
+		require.NoError(t, typed.Claims.Get(&custom))
+		assert.Equal(t, "Bear", custom.Name)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		token := NewToken(alg.HS256)
+		require.NoError(t, token.Claims.Set(map[string]interface{}{"name": 1}))
+
+		_, err := Typed[typedTestClaims](token)
+		var typeErr *json.UnmarshalTypeError
+		assert.True(t, errors.As(err, &typeErr))
+	})
+}
+
+func TestTypedToken_Token(t *testing.T) {
+	typed := NewTypedToken(alg.HS256, typedTestClaims{
+		BasicClaims: BasicClaims{Issuer: "issuer"},
+		Name:        "Bear",
+	})
+	typed.Claims.Name = "Changed"
+
+	token, err := typed.Token()
+	require.NoError(t, err)
+	assert.Equal(t, typed.Header, token.Header)
+	assert.Equal(t, "issuer", token.Claims.Issuer)
+
+	var custom typedTestClaims
+	require.NoError(t, token.Claims.Get(&custom))
+	assert.Equal(t, "Changed", custom.Name)
+
+	assert.NoError(t, NewValidator(WithIssuer("issuer")).Validate(token))
+}
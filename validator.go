@@ -0,0 +1,214 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenInactive    = errors.New("token is not active yet")
+	ErrTokenNotIssued   = errors.New("token is issued in the future")
+	ErrIssuerMismatch   = errors.New("token issuer is not accepted")
+	ErrAudienceMismatch = errors.New("token is not intended for this audience")
+	ErrMissingClaim     = errors.New("required claim is missing")
+)
+
+// tokenErrors are returned by checks rejecting the token itself, see IsTokenError
+var tokenErrors = []error{
+	ErrTokenExpired,
+	ErrTokenInactive,
+	ErrTokenNotIssued,
+	ErrIssuerMismatch,
+	ErrAudienceMismatch,
+	ErrMissingClaim,
+	ErrInvalidClaim,
+	ErrAudienceTypeMismatch,
+	ErrUnsupportedType,
+	ErrIncorrectSignature,
+	ErrMalformedId,
+	ErrTokenReplayed,
+	ErrTokenRevoked,
+	ErrAuthorizedPartyMismatch,
+	ErrNonceMismatch,
+	ErrAuthenticationExpired,
+	ErrAcrMismatch,
+	ErrAmrMismatch,
+	ErrTokenHashMismatch,
+	ErrConfirmationMismatch,
+	ErrClientMismatch,
+	ErrSecurityEventMisuse,
+	ErrActorNotAllowed,
+	ErrActorChainTooLong,
+}
+
+// IsTokenError reports whether err returned by Validator means the token is not acceptable.
+// Other errors are failures of backends used by checks, e.g. replay caches or revocation lists
+func IsTokenError(err error) bool {
+	for _, target := range tokenErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check is additional validation step, it returns error if token is not acceptable
+type Check func(t Token) error
+
+// ValidatorOption configures Validator
+type ValidatorOption func(v *Validator)
+
+// WithClock replaces time source, time.Now is used by default
+func WithClock(clock func() time.Time) ValidatorOption {
+	return func(v *Validator) {
+		v.clock = clock
+	}
+}
+
+// WithLeeway allows clock skew between issuer and validator when checking "exp", "nbf" and "iat"
+func WithLeeway(leeway time.Duration) ValidatorOption {
+	return func(v *Validator) {
+		v.leeway = leeway
+	}
+}
+
+// WithIssuer makes "iss" required, it must be equal to one of issuers
+func WithIssuer(issuers ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.issuers = issuers
+	}
+}
+
+// WithAudience makes "aud" required, it must contain at least one of audiences
+func WithAudience(audiences ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.audiences = audiences
+	}
+}
+
+// RequireExpiration rejects tokens without "exp"
+func RequireExpiration() ValidatorOption {
+	return func(v *Validator) {
+		v.requireExp = true
+	}
+}
+
+// WithCheck adds custom validation step, checks are executed in order after the standard ones.
+// Errors rejecting the token should wrap one of validation errors recognized by IsTokenError
+func WithCheck(check Check) ValidatorOption {
+	return withRule(func(_ Validator, t Token) error {
+		return check(t)
+	})
+}
+
+// rule is validation step which depends on validator settings, e.g. its clock
+type rule func(v Validator, t Token) error
+
+func withRule(r rule) ValidatorOption {
+	return func(v *Validator) {
+		v.rules = append(v.rules, r)
+	}
+}
+
+// Validator checks registered claims of parsed tokens
+type Validator struct {
+	clock       func() time.Time
+	leeway      time.Duration
+	issuers     []string
+	audiences   []string
+	requireExp  bool
+	rules       []rule
+	replayCache ReplayCache
+}
+
+// NewValidator returns Validator with applied options.
+// By default, only "exp", "nbf" and "iat" are checked if present
+func NewValidator(options ...ValidatorOption) Validator {
+	v := Validator{
+		clock: time.Now,
+	}
+	for _, option := range options {
+		option(&v)
+	}
+
+	return v
+}
+
+// Now returns current time of the validator clock, time.Now is used if the clock is not set (zero Validator)
+func (v Validator) Now() time.Time {
+	if v.clock == nil {
+		return time.Now()
+	}
+
+	return v.clock()
+}
+
+// Validate returns nil if the token is acceptable or the reason why it is not
+func (v Validator) Validate(t Token) error {
+	if err := v.validateTime(t.Claims); err != nil {
+		return err
+	}
+
+	if len(v.issuers) > 0 && !containsConstTime(v.issuers, t.Claims.Issuer) {
+		return fmt.Errorf("%w: \"%s\"", ErrIssuerMismatch, t.Claims.Issuer)
+	}
+	if len(v.audiences) > 0 && !v.isAudience(t.Claims) {
+		return ErrAudienceMismatch
+	}
+
+	for _, r := range v.rules {
+		if err := r(v, t); err != nil {
+			return err
+		}
+	}
+
+	if v.replayCache != nil {
+		return v.checkReplay(t.Claims)
+	}
+
+	return nil
+}
+
+func (v Validator) validateTime(c Claims) error {
+	now := v.Now()
+	if c.ExpiresAt == nil {
+		if v.requireExp {
+			return fmt.Errorf("%w: exp", ErrMissingClaim)
+		}
+	} else if now.Add(-v.leeway).After(c.ExpiresAt.Time) {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore != nil && now.Add(v.leeway).Before(c.NotBefore.Time) {
+		return ErrTokenInactive
+	}
+	if c.IssuedAt != nil && now.Add(v.leeway).Before(c.IssuedAt.Time) {
+		return ErrTokenNotIssued
+	}
+
+	return nil
+}
+
+func (v Validator) isAudience(c Claims) bool {
+	for _, audience := range v.audiences {
+		if c.IsAudience(audience) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsConstTime(values []string, value string) bool {
+	found := false
+	for _, v := range values {
+		if isConstTimeEqualsString(v, value) {
+			found = true
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	now := time.Date(2022, 6, 12, 5, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+
+	token := Token{}
+	token.Claims.Issuer = "auth"
+	token.Claims.Audience = Audience{"api", "office"}
+	token.Claims.IssuedAt = NewPosixTime(now.Add(-time.Minute))
+	token.Claims.ExpiresAt = NewPosixTime(now.Add(time.Minute))
+
+	t.Run("valid", func(t *testing.T) {
+		v := NewValidator(WithClock(clock), WithIssuer("other", "auth"), WithAudience("office"), RequireExpiration())
+		require.NoError(t, v.Validate(token))
+		assert.Equal(t, now, v.Now())
+	})
+	t.Run("time", func(t *testing.T) {
+		expired := token
+		expired.Claims.ExpiresAt = NewPosixTime(now.Add(-5 * time.Second))
+		require.Equal(t, ErrTokenExpired, NewValidator(WithClock(clock)).Validate(expired))
+		require.NoError(t, NewValidator(WithClock(clock), WithLeeway(10*time.Second)).Validate(expired))
+
+		inactive := token
+		inactive.Claims.NotBefore = NewPosixTime(now.Add(time.Minute))
+		require.Equal(t, ErrTokenInactive, NewValidator(WithClock(clock)).Validate(inactive))
+
+		notIssued := token
+		notIssued.Claims.IssuedAt = NewPosixTime(now.Add(time.Minute))
+		require.Equal(t, ErrTokenNotIssued, NewValidator(WithClock(clock)).Validate(notIssued))
+		require.NoError(t, NewValidator(WithClock(clock), WithLeeway(time.Minute)).Validate(notIssued))
+
+		eternal := token
+		eternal.Claims.ExpiresAt = nil
+		require.NoError(t, NewValidator(WithClock(clock)).Validate(eternal))
+		require.True(t, errors.Is(NewValidator(WithClock(clock), RequireExpiration()).Validate(eternal), ErrMissingClaim))
+	})
+	t.Run("issuer and audience", func(t *testing.T) {
+		err := NewValidator(WithClock(clock), WithIssuer("other")).Validate(token)
+		require.True(t, errors.Is(err, ErrIssuerMismatch))
+
+		err = NewValidator(WithClock(clock), WithAudience("admin")).Validate(token)
+		require.Equal(t, ErrAudienceMismatch, err)
+	})
+	t.Run("custom check", func(t *testing.T) {
+		fail := errors.New("fail")
+		calls := 0
+		v := NewValidator(WithClock(clock), WithCheck(func(t Token) error {
+			calls++
+			return nil
+		}), WithCheck(func(t Token) error {
+			return fail
+		}))
+
+		require.Equal(t, fail, v.Validate(token))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("token errors", func(t *testing.T) {
+		assert.True(t, IsTokenError(ErrTokenExpired))
+		assert.True(t, IsTokenError(fmt.Errorf("%w: sub", ErrMissingClaim)))
+		assert.False(t, IsTokenError(errors.New("connection refused")))
+		assert.True(t, IsTokenError(ErrTokenReplayed))
+		assert.False(t, IsTokenError(ErrReplayCacheFull))
+		assert.False(t, IsTokenError(nil))
+	})
+	t.Run("zero value", func(t *testing.T) {
+		v := Validator{}
+		assert.WithinDuration(t, time.Now(), v.Now(), time.Minute)
+
+		fresh := Token{}
+		fresh.Claims.ExpiresAt = NewPosixTime(time.Now().Add(time.Minute))
+		require.NoError(t, v.Validate(fresh))
+		require.Equal(t, ErrTokenExpired, v.Validate(token))
+	})
+}